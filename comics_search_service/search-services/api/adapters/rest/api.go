@@ -1,6 +1,7 @@
 package rest
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -8,6 +9,8 @@ import (
 	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"yadro.com/course/api/core"
 )
@@ -76,15 +79,89 @@ func NewUpdateHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if err := updater.Update(r.Context()); err != nil {
 			log.Error("error while update", "error", err)
-			if errors.Is(err, core.ErrAlreadyExists) {
+			switch {
+			case errors.Is(err, core.ErrAlreadyExists):
+				http.Error(w, err.Error(), http.StatusAccepted)
+				return
+			case errors.Is(err, core.ErrCanceled):
+				http.Error(w, err.Error(), http.StatusConflict)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	}
+}
+
+func NewRetryFailedHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := updater.RetryFailed(r.Context()); err != nil {
+			log.Error("error while retry", "error", err)
+			switch {
+			case errors.Is(err, core.ErrAlreadyExists):
 				http.Error(w, err.Error(), http.StatusAccepted)
 				return
+			case errors.Is(err, core.ErrCanceled):
+				http.Error(w, err.Error(), http.StatusConflict)
+				return
 			}
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 		}
 	}
 }
 
+func NewReindexHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := updater.Reindex(r.Context()); err != nil {
+			log.Error("error while reindex", "error", err)
+			switch {
+			case errors.Is(err, core.ErrAlreadyExists):
+				http.Error(w, err.Error(), http.StatusAccepted)
+				return
+			case errors.Is(err, core.ErrCanceled):
+				http.Error(w, err.Error(), http.StatusConflict)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	}
+}
+
+type UpdateFailure struct {
+	ID       int    `json:"id"`
+	Stage    string `json:"stage"`
+	Error    string `json:"error"`
+	Attempts int    `json:"attempts"`
+	Updated  string `json:"updated"`
+}
+
+type FailuresReply struct {
+	Failures []UpdateFailure `json:"failures"`
+}
+
+func NewFailuresHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		failures, err := updater.Failures(r.Context())
+		if err != nil {
+			log.Error("error while failures", "error", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		reply := FailuresReply{Failures: make([]UpdateFailure, 0, len(failures))}
+		for _, f := range failures {
+			reply.Failures = append(reply.Failures, UpdateFailure{
+				ID:       f.ID,
+				Stage:    f.Stage,
+				Error:    f.Error,
+				Attempts: f.Attempts,
+				Updated:  f.Updated.Format(time.RFC3339),
+			})
+		}
+		if err := encodeReply(w, reply); err != nil {
+			log.Error("cannot encode reply", "error", err)
+		}
+	}
+}
+
 type UpdateStats struct {
 	WordsTotal    int `json:"words_total"`
 	WordsUnique   int `json:"words_unique"`
@@ -113,19 +190,116 @@ func NewUpdateStatsHandler(log *slog.Logger, updater core.Updater) http.HandlerF
 }
 
 type UpdateStatus struct {
-	Status string `json:"status"`
+	Status   string          `json:"status"`
+	LastRun  string          `json:"last_run,omitempty"`
+	NextRun  string          `json:"next_run,omitempty"`
+	Progress *UpdateProgress `json:"progress,omitempty"`
+}
+
+type UpdateProgress struct {
+	Started    string `json:"started"`
+	Finished   string `json:"finished,omitempty"`
+	Planned    int    `json:"planned"`
+	Fetched    int    `json:"fetched"`
+	Normalized int    `json:"normalized"`
+	Stored     int    `json:"stored"`
+	Failed     int    `json:"failed"`
+	ETASeconds int    `json:"eta_seconds,omitempty"`
+	Canceled   bool   `json:"canceled,omitempty"`
 }
 
 func NewUpdateStatusHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		status, err := updater.Status(r.Context())
+		info, err := updater.Status(r.Context())
 		if err != nil {
 			log.Error("error while status", "error", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
-		reply := UpdateStatus{Status: string(status)}
-		if err := encodeReply(w, reply); err != nil {
+		if err := encodeReply(w, updateStatus(info)); err != nil {
+			log.Error("cannot encode reply", "error", err)
+		}
+	}
+}
+
+// NewUpdateStatusStreamHandler sends the update status and its changes as Server-Sent Events.
+func NewUpdateStatusStreamHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+			return
+		}
+		statuses, err := updater.WatchStatus(r.Context())
+		if err != nil {
+			log.Error("error while watching status", "error", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+		for info := range statuses {
+			data, err := json.Marshal(updateStatus(info))
+			if err != nil {
+				log.Error("cannot encode status", "error", err)
+				return
+			}
+			if _, err := fmt.Fprintf(w, "event: status\ndata: %s\n\n", data); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func updateStatus(info core.UpdateStatusInfo) UpdateStatus {
+	reply := UpdateStatus{Status: string(info.Status)}
+	if !info.LastRun.IsZero() {
+		reply.LastRun = info.LastRun.Format(time.RFC3339)
+	}
+	if !info.NextRun.IsZero() {
+		reply.NextRun = info.NextRun.Format(time.RFC3339)
+	}
+	if !info.Progress.Started.IsZero() {
+		progress := updateProgress(info.Progress)
+		reply.Progress = &progress
+	}
+	return reply
+}
+
+func updateProgress(p core.UpdateProgress) UpdateProgress {
+	reply := UpdateProgress{
+		Started:    p.Started.Format(time.RFC3339),
+		Planned:    p.Planned,
+		Fetched:    p.Fetched,
+		Normalized: p.Normalized,
+		Stored:     p.Stored,
+		Failed:     p.Failed,
+		ETASeconds: int(p.ETA.Round(time.Second).Seconds()),
+		Canceled:   p.Canceled,
+	}
+	if !p.Finished.IsZero() {
+		reply.Finished = p.Finished.Format(time.RFC3339)
+	}
+	return reply
+}
+
+// NewCancelUpdateHandler stops the running update and replies with its partial result.
+func NewCancelUpdateHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		progress, err := updater.Cancel(r.Context())
+		if err != nil {
+			log.Error("error while cancel", "error", err)
+			if errors.Is(err, core.ErrNotFound) {
+				http.Error(w, "no update runs", http.StatusNotFound)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := encodeReply(w, updateProgress(progress)); err != nil {
 			log.Error("cannot encode reply", "error", err)
 		}
 	}
@@ -135,118 +309,362 @@ func NewDropHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if err := updater.Drop(r.Context()); err != nil {
 			log.Error("error while drop", "error", err)
+			if errors.Is(err, core.ErrAlreadyExists) {
+				http.Error(w, "update is running", http.StatusConflict)
+				return
+			}
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 		}
 	}
 }
 
 type Comics struct {
-	ID    int    `json:"id"`
-	URL   string `json:"url"`
-	Score int    `json:"score"`
+	ID      int     `json:"id"`
+	URL     string  `json:"url"`
+	Score   float64 `json:"score"`
+	Snippet string  `json:"snippet,omitempty"`
 }
 
 type ComicsReply struct {
-	Comics []Comics `json:"comics"`
-	Total  int      `json:"total"`
+	Comics        []Comics `json:"comics"`
+	Total         int      `json:"total"`
+	NextPageToken string   `json:"next_page_token,omitempty"`
+	Suggestion    string   `json:"suggestion,omitempty"`
+	Corrected     bool     `json:"corrected,omitempty"`
 }
 
 func NewSearchHandler(log *slog.Logger, searcher core.Searcher) http.HandlerFunc {
+	return newSearchHandler(log, searcher.Search)
+}
+
+func NewSearchIndexHandler(log *slog.Logger, searcher core.Searcher) http.HandlerFunc {
+	return newSearchHandler(log, searcher.SearchIndex)
+}
+
+type searchFunc func(context.Context, core.SearchRequest) (core.SearchResult, error)
+
+func newSearchHandler(log *slog.Logger, search searchFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		var limit int
-		var err error
-		limitStr := r.URL.Query().Get("limit")
-		if limitStr != "" {
-			limit, err = strconv.Atoi(limitStr)
-			if err != nil {
-				log.Error("wrong limit", "value", limitStr)
-				http.Error(w, "bad limit", http.StatusBadRequest)
-				return
-			}
-			if limit < 0 {
-				log.Error("wrong limit", "value", limit)
-				http.Error(w, "bad limit", http.StatusBadRequest)
-				return
-			}
-		}
-		phrase := r.URL.Query().Get("phrase")
-		if phrase == "" {
-			log.Error("no phrase")
-			http.Error(w, "no phrase", http.StatusBadRequest)
+		req, err := parseSearchRequest(r)
+		if err != nil {
+			log.Error("wrong search request", "error", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
 
-		comics, err := searcher.Search(r.Context(), phrase, limit)
+		result, err := search(r.Context(), req)
 		if err != nil {
 			if errors.Is(err, core.ErrNotFound) {
 				http.Error(w, "no comics found", http.StatusNotFound)
 				return
 			}
+			if errors.Is(err, core.ErrBadArguments) {
+				log.Error("bad search query", "phrase", req.Phrase, "error", err)
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
 			log.Error("error while seaching", "error", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
 
-		reply := ComicsReply{
-			Comics: make([]Comics, 0, len(comics)),
-			Total:  len(comics),
+		if err := encodeReply(w, comicsReply(result)); err != nil {
+			log.Error("cannot encode reply", "error", err)
 		}
-		for _, c := range comics {
-			reply.Comics = append(reply.Comics, Comics{ID: c.ID, URL: c.URL, Score: c.Score})
+	}
+}
+
+func comicsReply(result core.SearchResult) ComicsReply {
+	reply := ComicsReply{
+		Comics:        make([]Comics, 0, len(result.Comics)),
+		Total:         result.Total,
+		NextPageToken: result.NextPageToken,
+		Suggestion:    result.Suggestion,
+		Corrected:     result.Corrected,
+	}
+	for _, c := range result.Comics {
+		reply.Comics = append(reply.Comics, Comics{
+			ID: c.ID, URL: c.URL, Score: c.Score, Snippet: c.Snippet,
+		})
+	}
+	return reply
+}
+
+func parseSearchRequest(r *http.Request) (core.SearchRequest, error) {
+	var req core.SearchRequest
+	var err error
+	query := r.URL.Query()
+	if limitStr := query.Get("limit"); limitStr != "" {
+		req.Limit, err = strconv.Atoi(limitStr)
+		if err != nil || req.Limit < 0 {
+			return core.SearchRequest{}, errors.New("bad limit")
+		}
+	}
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		req.Offset, err = strconv.Atoi(offsetStr)
+		if err != nil || req.Offset < 0 {
+			return core.SearchRequest{}, errors.New("bad offset")
+		}
+	}
+	req.PageToken = query.Get("page_token")
+	if req.Offset > 0 && req.PageToken != "" {
+		return core.SearchRequest{}, errors.New("offset and page_token are mutually exclusive")
+	}
+	if fuzzyStr := query.Get("fuzzy"); fuzzyStr != "" {
+		req.Fuzzy, err = strconv.ParseBool(fuzzyStr)
+		if err != nil {
+			return core.SearchRequest{}, errors.New("bad fuzzy")
+		}
+	}
+	if autocorrectStr := query.Get("autocorrect"); autocorrectStr != "" {
+		req.AutoCorrect, err = strconv.ParseBool(autocorrectStr)
+		if err != nil {
+			return core.SearchRequest{}, errors.New("bad autocorrect")
+		}
+	}
+	if fromStr := query.Get("from"); fromStr != "" {
+		req.From, _, err = parseDate(fromStr)
+		if err != nil {
+			return core.SearchRequest{}, errors.New("bad from")
+		}
+	}
+	if toStr := query.Get("to"); toStr != "" {
+		_, req.To, err = parseDate(toStr)
+		if err != nil {
+			return core.SearchRequest{}, errors.New("bad to")
+		}
+	}
+	if minIDStr := query.Get("min_id"); minIDStr != "" {
+		req.MinID, err = strconv.Atoi(minIDStr)
+		if err != nil || req.MinID < 0 {
+			return core.SearchRequest{}, errors.New("bad min_id")
+		}
+	}
+	if maxIDStr := query.Get("max_id"); maxIDStr != "" {
+		req.MaxID, err = strconv.Atoi(maxIDStr)
+		if err != nil || req.MaxID < 0 {
+			return core.SearchRequest{}, errors.New("bad max_id")
+		}
+	}
+	req.Phrase = query.Get("phrase")
+	if req.Phrase == "" {
+		return core.SearchRequest{}, errors.New("no phrase")
+	}
+	return req, nil
+}
+
+// parseDate parses a year, a month or a day like 2010, 2010-05 or 2010-05-17
+// and returns the first and the last days of the period.
+func parseDate(value string) (time.Time, time.Time, error) {
+	for _, period := range []struct {
+		layout string
+		years  int
+		months int
+		days   int
+	}{
+		{layout: "2006", years: 1},
+		{layout: "2006-01", months: 1},
+		{layout: time.DateOnly, days: 1},
+	} {
+		first, err := time.Parse(period.layout, value)
+		if err == nil {
+			return first, first.AddDate(period.years, period.months, period.days-1), nil
 		}
+	}
+	return time.Time{}, time.Time{}, fmt.Errorf("bad date %q", value)
+}
 
-		if err := encodeReply(w, reply); err != nil {
+type ComicsInfo struct {
+	ID         int    `json:"id"`
+	URL        string `json:"url"`
+	Title      string `json:"title"`
+	SafeTitle  string `json:"safe_title"`
+	Alt        string `json:"alt"`
+	Transcript string `json:"transcript"`
+	Published  string `json:"published,omitempty"`
+	Permalink  string `json:"permalink"`
+}
+
+func NewComicsHandler(log *slog.Logger, searcher core.Searcher) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ID, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil || ID < 1 {
+			http.Error(w, "bad id", http.StatusBadRequest)
+			return
+		}
+		comics, err := searcher.Get(r.Context(), ID)
+		if err != nil {
+			if errors.Is(err, core.ErrNotFound) {
+				http.Error(w, "no comics found", http.StatusNotFound)
+				return
+			}
+			log.Error("error while getting comics", "id", ID, "error", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := encodeReply(w, comicsInfo(comics)); err != nil {
 			log.Error("cannot encode reply", "error", err)
 		}
 	}
 }
 
-func NewSearchIndexHandler(log *slog.Logger, searcher core.Searcher) http.HandlerFunc {
+func NewRelatedHandler(log *slog.Logger, searcher core.Searcher) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		ID, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil || ID < 1 {
+			http.Error(w, "bad id", http.StatusBadRequest)
+			return
+		}
 		var limit int
-		var err error
-		limitStr := r.URL.Query().Get("limit")
-		if limitStr != "" {
+		if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
 			limit, err = strconv.Atoi(limitStr)
-			if err != nil {
-				log.Error("wrong limit", "value", limitStr)
+			if err != nil || limit < 0 {
 				http.Error(w, "bad limit", http.StatusBadRequest)
 				return
 			}
-			if limit < 0 {
-				log.Error("wrong limit", "value", limit)
+		}
+		result, err := searcher.Related(r.Context(), ID, limit)
+		if err != nil {
+			if errors.Is(err, core.ErrNotFound) {
+				http.Error(w, "no comics found", http.StatusNotFound)
+				return
+			}
+			log.Error("error while searching related comics", "id", ID, "error", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := encodeReply(w, comicsReply(result)); err != nil {
+			log.Error("cannot encode reply", "error", err)
+		}
+	}
+}
+
+type Suggestion struct {
+	Text      string `json:"text"`
+	Keyword   string `json:"keyword"`
+	Frequency int    `json:"frequency"`
+}
+
+type SuggestReply struct {
+	Suggestions []Suggestion `json:"suggestions"`
+}
+
+func NewSuggestHandler(log *slog.Logger, searcher core.Searcher) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		prefix := query.Get("prefix")
+		if strings.TrimSpace(prefix) == "" {
+			http.Error(w, "empty prefix", http.StatusBadRequest)
+			return
+		}
+		var limit int
+		if limitStr := query.Get("limit"); limitStr != "" {
+			var err error
+			limit, err = strconv.Atoi(limitStr)
+			if err != nil || limit < 0 {
 				http.Error(w, "bad limit", http.StatusBadRequest)
 				return
 			}
 		}
-		phrase := r.URL.Query().Get("phrase")
-		if phrase == "" {
-			log.Error("no phrase")
-			http.Error(w, "no phrase", http.StatusBadRequest)
+		suggestions, err := searcher.Suggest(r.Context(), prefix, limit)
+		if err != nil {
+			if errors.Is(err, core.ErrBadArguments) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			log.Error("error while suggesting words", "prefix", prefix, "error", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
+		reply := SuggestReply{Suggestions: make([]Suggestion, 0, len(suggestions))}
+		for _, s := range suggestions {
+			reply.Suggestions = append(reply.Suggestions, Suggestion{
+				Text: s.Text, Keyword: s.Keyword, Frequency: s.Frequency,
+			})
+		}
+		if err := encodeReply(w, reply); err != nil {
+			log.Error("cannot encode reply", "error", err)
+		}
+	}
+}
+
+func comicsInfo(comics core.ComicsInfo) ComicsInfo {
+	info := ComicsInfo{
+		ID:         comics.ID,
+		URL:        comics.URL,
+		Title:      comics.Title,
+		SafeTitle:  comics.SafeTitle,
+		Alt:        comics.Alt,
+		Transcript: comics.Transcript,
+		Permalink:  comics.Permalink,
+	}
+	if !comics.Published.IsZero() {
+		info.Published = comics.Published.Format(time.DateOnly)
+	}
+	return info
+}
 
-		comics, err := searcher.SearchIndex(r.Context(), phrase, limit)
+type ListReply struct {
+	Comics []ComicsInfo `json:"comics"`
+	Total  int          `json:"total"`
+}
+
+func NewListHandler(log *slog.Logger, searcher core.Searcher) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := parseListRequest(r)
 		if err != nil {
-			if errors.Is(err, core.ErrNotFound) {
-				http.Error(w, "no comics found", http.StatusNotFound)
+			log.Error("wrong list request", "error", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		result, err := searcher.List(r.Context(), req)
+		if err != nil {
+			if errors.Is(err, core.ErrBadArguments) {
+				http.Error(w, "bad list request", http.StatusBadRequest)
 				return
 			}
-			log.Error("error while seaching", "error", err)
+			log.Error("error while listing comics", "error", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
-
-		reply := ComicsReply{
-			Comics: make([]Comics, 0, len(comics)),
-			Total:  len(comics),
+		reply := ListReply{
+			Comics: make([]ComicsInfo, 0, len(result.Comics)),
+			Total:  result.Total,
 		}
-		for _, c := range comics {
-			reply.Comics = append(reply.Comics, Comics{ID: c.ID, URL: c.URL, Score: c.Score})
+		for _, c := range result.Comics {
+			reply.Comics = append(reply.Comics, comicsInfo(c))
 		}
-
 		if err := encodeReply(w, reply); err != nil {
 			log.Error("cannot encode reply", "error", err)
 		}
 	}
 }
+
+func parseListRequest(r *http.Request) (core.ListRequest, error) {
+	req := core.ListRequest{Order: core.ListByID}
+	var err error
+	query := r.URL.Query()
+	if limitStr := query.Get("limit"); limitStr != "" {
+		req.Limit, err = strconv.Atoi(limitStr)
+		if err != nil || req.Limit < 0 {
+			return core.ListRequest{}, errors.New("bad limit")
+		}
+	}
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		req.Offset, err = strconv.Atoi(offsetStr)
+		if err != nil || req.Offset < 0 {
+			return core.ListRequest{}, errors.New("bad offset")
+		}
+	}
+	if sort := query.Get("sort"); sort != "" {
+		req.Order = core.ListOrder(sort)
+		switch req.Order {
+		case core.ListByID, core.ListByIDDesc,
+			core.ListByPublished, core.ListByPublishedDesc,
+			core.ListByKeywords, core.ListByKeywordsDesc:
+		default:
+			return core.ListRequest{}, errors.New("bad sort")
+		}
+	}
+	return req, nil
+}
@@ -2,12 +2,15 @@ package search
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"strings"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 	"yadro.com/course/api/core"
 	searchpb "yadro.com/course/proto/search"
 )
@@ -34,38 +37,146 @@ func (c *Client) Close() error {
 	return c.conn.Close()
 }
 
-func (c *Client) Search(ctx context.Context, phrase string, limit int) ([]core.Comics, error) {
-	reply, err := c.client.Search(ctx, &searchpb.SearchRequest{
-		Phrase: phrase, Limit: int64(limit),
-	})
+func (c *Client) Search(ctx context.Context, req core.SearchRequest) (core.SearchResult, error) {
+	reply, err := c.client.Search(ctx, searchRequest(req))
 	if err != nil {
-		if status.Code(err) == codes.NotFound {
-			return nil, core.ErrNotFound
-		}
-		return nil, err
+		return core.SearchResult{}, searchError(err)
 	}
-	comics := make([]core.Comics, 0, len(reply.Comics))
-	for _, c := range reply.Comics {
-		comics = append(comics, core.Comics{ID: int(c.Id), URL: c.Url, Score: int(c.Score)})
+	return searchResult(reply), nil
+}
+
+func (c *Client) SearchIndex(ctx context.Context, req core.SearchRequest) (core.SearchResult, error) {
+	reply, err := c.client.SearchIndex(ctx, searchRequest(req))
+	if err != nil {
+		return core.SearchResult{}, searchError(err)
+	}
+	return searchResult(reply), nil
+}
+
+func (c *Client) Get(ctx context.Context, ID int) (core.ComicsInfo, error) {
+	reply, err := c.client.Get(ctx, &searchpb.GetRequest{Id: int64(ID)})
+	if err != nil {
+		return core.ComicsInfo{}, searchError(err)
 	}
-	return comics, nil
+	return comicsInfo(reply), nil
+}
+
+func (c *Client) Related(ctx context.Context, ID, limit int) (core.SearchResult, error) {
+	reply, err := c.client.Related(ctx, &searchpb.RelatedRequest{Id: int64(ID), Limit: int64(limit)})
+	if err != nil {
+		return core.SearchResult{}, searchError(err)
+	}
+	return searchResult(reply), nil
+}
+
+func (c *Client) Suggest(ctx context.Context, prefix string, limit int) ([]core.Suggestion, error) {
+	reply, err := c.client.Suggest(ctx, &searchpb.SuggestRequest{Prefix: prefix, Limit: int64(limit)})
+	if err != nil {
+		return nil, searchError(err)
+	}
+	suggestions := make([]core.Suggestion, 0, len(reply.Suggestions))
+	for _, s := range reply.Suggestions {
+		suggestions = append(suggestions, core.Suggestion{
+			Text: s.Text, Keyword: s.Keyword, Frequency: int(s.Frequency),
+		})
+	}
+	return suggestions, nil
+}
+
+var listOrders = map[core.ListOrder]searchpb.ListOrder{
+	core.ListByID:            searchpb.ListOrder_LIST_ORDER_ID_ASC,
+	core.ListByIDDesc:        searchpb.ListOrder_LIST_ORDER_ID_DESC,
+	core.ListByPublished:     searchpb.ListOrder_LIST_ORDER_PUBLISHED_ASC,
+	core.ListByPublishedDesc: searchpb.ListOrder_LIST_ORDER_PUBLISHED_DESC,
+	core.ListByKeywords:      searchpb.ListOrder_LIST_ORDER_KEYWORDS_ASC,
+	core.ListByKeywordsDesc:  searchpb.ListOrder_LIST_ORDER_KEYWORDS_DESC,
 }
 
-func (c *Client) SearchIndex(ctx context.Context, phrase string, limit int) ([]core.Comics, error) {
-	reply, err := c.client.SearchIndex(ctx, &searchpb.SearchRequest{
-		Phrase: phrase, Limit: int64(limit),
+func (c *Client) List(ctx context.Context, req core.ListRequest) (core.ListResult, error) {
+	order, ok := listOrders[req.Order]
+	if !ok {
+		return core.ListResult{}, fmt.Errorf("%w: unknown order %q", core.ErrBadArguments, req.Order)
+	}
+	reply, err := c.client.List(ctx, &searchpb.ListRequest{
+		Limit:  int64(req.Limit),
+		Offset: int64(req.Offset),
+		Order:  order,
 	})
 	if err != nil {
-		if status.Code(err) == codes.NotFound {
-			return nil, core.ErrNotFound
-		}
-		return nil, err
+		return core.ListResult{}, searchError(err)
+	}
+	result := core.ListResult{
+		Comics: make([]core.ComicsInfo, 0, len(reply.Comics)),
+		Total:  int(reply.Total),
 	}
+	for _, c := range reply.Comics {
+		result.Comics = append(result.Comics, comicsInfo(c))
+	}
+	return result, nil
+}
+
+func comicsInfo(reply *searchpb.ComicsInfo) core.ComicsInfo {
+	info := core.ComicsInfo{
+		ID:         int(reply.Id),
+		URL:        reply.Url,
+		Title:      reply.Title,
+		SafeTitle:  reply.SafeTitle,
+		Alt:        reply.Alt,
+		Transcript: reply.Transcript,
+		Permalink:  reply.Permalink,
+	}
+	if reply.Published != nil {
+		info.Published = reply.Published.AsTime()
+	}
+	return info
+}
+
+func searchRequest(req core.SearchRequest) *searchpb.SearchRequest {
+	result := &searchpb.SearchRequest{
+		Phrase:      req.Phrase,
+		Limit:       int64(req.Limit),
+		Offset:      int64(req.Offset),
+		PageToken:   req.PageToken,
+		Fuzzy:       req.Fuzzy,
+		MinId:       int64(req.MinID),
+		MaxId:       int64(req.MaxID),
+		AutoCorrect: req.AutoCorrect,
+	}
+	if !req.From.IsZero() {
+		result.From = timestamppb.New(req.From)
+	}
+	if !req.To.IsZero() {
+		result.To = timestamppb.New(req.To)
+	}
+	return result
+}
+
+func searchError(err error) error {
+	switch status.Code(err) {
+	case codes.NotFound:
+		return core.ErrNotFound
+	case codes.InvalidArgument:
+		// search tells arguments are not acceptable in the message too
+		reason := strings.TrimPrefix(status.Convert(err).Message(), core.ErrBadArguments.Error()+": ")
+		return fmt.Errorf("%w: %s", core.ErrBadArguments, reason)
+	}
+	return err
+}
+
+func searchResult(reply *searchpb.SearchReply) core.SearchResult {
 	comics := make([]core.Comics, 0, len(reply.Comics))
 	for _, c := range reply.Comics {
-		comics = append(comics, core.Comics{ID: int(c.Id), URL: c.Url, Score: int(c.Score)})
+		comics = append(comics, core.Comics{
+			ID: int(c.Id), URL: c.Url, Score: c.Score, Snippet: c.Snippet,
+		})
+	}
+	return core.SearchResult{
+		Comics:        comics,
+		Total:         int(reply.Total),
+		NextPageToken: reply.NextPageToken,
+		Suggestion:    reply.Suggestion,
+		Corrected:     reply.Corrected,
 	}
-	return comics, nil
 }
 
 func (c *Client) Ping(ctx context.Context) error {
@@ -40,18 +40,84 @@ func (c *Client) Ping(ctx context.Context) error {
 	return err
 }
 
-func (c *Client) Status(ctx context.Context) (core.UpdateStatus, error) {
+func (c *Client) Status(ctx context.Context) (core.UpdateStatusInfo, error) {
 	reply, err := c.client.Status(ctx, nil)
 	if err != nil {
-		return core.StatusUpdateUnknown, err
+		return core.UpdateStatusInfo{Status: core.StatusUpdateUnknown}, err
 	}
+	return statusInfo(reply)
+}
+
+func (c *Client) WatchStatus(ctx context.Context) (<-chan core.UpdateStatusInfo, error) {
+	stream, err := c.client.WatchStatus(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	ch := make(chan core.UpdateStatusInfo)
+	go func() {
+		defer close(ch)
+		for {
+			reply, err := stream.Recv()
+			if err != nil {
+				if ctx.Err() == nil {
+					c.log.Warn("status stream broken", "error", err)
+				}
+				return
+			}
+			info, err := statusInfo(reply)
+			if err != nil {
+				c.log.Warn("bad status in stream", "error", err)
+				return
+			}
+			select {
+			case ch <- info:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+func statusInfo(reply *updatepb.StatusReply) (core.UpdateStatusInfo, error) {
+	info := core.UpdateStatusInfo{}
 	switch reply.Status {
 	case updatepb.Status_STATUS_IDLE:
-		return core.StatusUpdateIdle, nil
+		info.Status = core.StatusUpdateIdle
 	case updatepb.Status_STATUS_RUNNING:
-		return core.StatusUpdateRunning, nil
+		info.Status = core.StatusUpdateRunning
+	default:
+		return core.UpdateStatusInfo{Status: core.StatusUpdateUnknown}, errors.New("unknown status")
+	}
+	if reply.LastRun != nil {
+		info.LastRun = reply.LastRun.AsTime()
 	}
-	return core.StatusUpdateUnknown, errors.New("unknown status")
+	if reply.NextRun != nil {
+		info.NextRun = reply.NextRun.AsTime()
+	}
+	if reply.Progress != nil {
+		info.Progress = progress(reply.Progress)
+	}
+	return info, nil
+}
+
+func progress(p *updatepb.Progress) core.UpdateProgress {
+	result := core.UpdateProgress{
+		Started:    p.Started.AsTime(),
+		Planned:    int(p.Planned),
+		Fetched:    int(p.Fetched),
+		Normalized: int(p.Normalized),
+		Stored:     int(p.Stored),
+		Failed:     int(p.Failed),
+		Canceled:   p.Canceled,
+	}
+	if p.Finished != nil {
+		result.Finished = p.Finished.AsTime()
+	}
+	if p.Eta != nil {
+		result.ETA = p.Eta.AsDuration()
+	}
+	return result
 }
 
 func (c *Client) Stats(ctx context.Context) (core.UpdateStats, error) {
@@ -69,13 +135,59 @@ func (c *Client) Stats(ctx context.Context) (core.UpdateStats, error) {
 
 func (c *Client) Update(ctx context.Context) error {
 	_, err := c.client.Update(ctx, nil)
-	if status.Code(err) == codes.AlreadyExists {
+	return updateError(err)
+}
+
+func updateError(err error) error {
+	switch status.Code(err) {
+	case codes.AlreadyExists:
 		return core.ErrAlreadyExists
+	case codes.Aborted:
+		return core.ErrCanceled
 	}
 	return err
 }
 
+func (c *Client) Cancel(ctx context.Context) (core.UpdateProgress, error) {
+	reply, err := c.client.Cancel(ctx, nil)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return core.UpdateProgress{}, core.ErrNotFound
+		}
+		return core.UpdateProgress{}, err
+	}
+	return progress(reply), nil
+}
+
+func (c *Client) RetryFailed(ctx context.Context) error {
+	_, err := c.client.RetryFailed(ctx, nil)
+	return updateError(err)
+}
+
+func (c *Client) Reindex(ctx context.Context) error {
+	_, err := c.client.Reindex(ctx, nil)
+	return updateError(err)
+}
+
+func (c *Client) Failures(ctx context.Context) ([]core.UpdateFailure, error) {
+	reply, err := c.client.Failures(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	failures := make([]core.UpdateFailure, 0, len(reply.GetFailures()))
+	for _, f := range reply.GetFailures() {
+		failures = append(failures, core.UpdateFailure{
+			ID:       int(f.GetId()),
+			Stage:    f.GetStage(),
+			Error:    f.GetError(),
+			Attempts: int(f.GetAttempts()),
+			Updated:  f.GetUpdated().AsTime(),
+		})
+	}
+	return failures, nil
+}
+
 func (c *Client) Drop(ctx context.Context) error {
 	_, err := c.client.Drop(ctx, nil)
-	return err
+	return updateError(err)
 }
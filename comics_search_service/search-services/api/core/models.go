@@ -1,5 +1,7 @@
 package core
 
+import "time"
+
 type UpdateStatus string
 
 const (
@@ -8,6 +10,35 @@ const (
 	StatusUpdateRunning UpdateStatus = "running"
 )
 
+type UpdateStatusInfo struct {
+	Status   UpdateStatus
+	LastRun  time.Time      // last scheduled check, zero if none yet
+	NextRun  time.Time      // zero if updates are not scheduled
+	Progress UpdateProgress // zero if there were no updates
+}
+
+// UpdateProgress is progress of the running or the last finished update.
+type UpdateProgress struct {
+	Started    time.Time
+	Finished   time.Time // zero while running
+	Planned    int
+	Fetched    int
+	Normalized int
+	Stored     int
+	Failed     int
+	ETA        time.Duration // zero if unknown
+	Canceled   bool
+}
+
+// UpdateFailure is a comics failed at some stage of the last update it took part in.
+type UpdateFailure struct {
+	ID       int
+	Stage    string // fetch, normalize or store
+	Error    string
+	Attempts int
+	Updated  time.Time
+}
+
 type UpdateStats struct {
 	WordsTotal    int
 	WordsUnique   int
@@ -15,8 +46,70 @@ type UpdateStats struct {
 	ComicsTotal   int
 }
 
+type SearchRequest struct {
+	Phrase    string
+	Limit     int
+	Offset    int
+	PageToken string
+	Fuzzy     bool
+	From      time.Time // zero if not restricted
+	To        time.Time // inclusive, zero if not restricted
+	MinID     int
+	MaxID     int
+	// AutoCorrect runs the suggested query when the original one finds nothing.
+	AutoCorrect bool
+}
+
 type Comics struct {
-	ID    int
-	URL   string
-	Score int
+	ID      int
+	URL     string
+	Score   float64
+	Snippet string
+}
+
+type ComicsInfo struct {
+	ID         int
+	URL        string
+	Title      string
+	SafeTitle  string
+	Alt        string
+	Transcript string
+	Published  time.Time // zero if unknown
+	Permalink  string
+}
+
+type ListOrder string
+
+const (
+	ListByID            ListOrder = "id"
+	ListByIDDesc        ListOrder = "-id"
+	ListByPublished     ListOrder = "published"
+	ListByPublishedDesc ListOrder = "-published"
+	ListByKeywords      ListOrder = "keywords"
+	ListByKeywordsDesc  ListOrder = "-keywords"
+)
+
+type ListRequest struct {
+	Limit  int
+	Offset int
+	Order  ListOrder
+}
+
+type ListResult struct {
+	Comics []ComicsInfo
+	Total  int
+}
+
+type SearchResult struct {
+	Comics        []Comics
+	Total         int
+	NextPageToken string
+	Suggestion    string // corrected query if the original one finds nothing
+	Corrected     bool   // comics are found by the suggestion
+}
+
+type Suggestion struct {
+	Text      string
+	Keyword   string
+	Frequency int
 }
@@ -12,12 +12,25 @@ type Pinger interface {
 
 type Updater interface {
 	Update(context.Context) error
+	// Cancel stops the running update and returns its progress once it is stopped.
+	Cancel(context.Context) (UpdateProgress, error)
 	Stats(context.Context) (UpdateStats, error)
-	Status(context.Context) (UpdateStatus, error)
+	Status(context.Context) (UpdateStatusInfo, error)
+	// WatchStatus sends the status and its changes until ctx is done or the stream breaks.
+	WatchStatus(context.Context) (<-chan UpdateStatusInfo, error)
+	Failures(context.Context) ([]UpdateFailure, error)
+	// RetryFailed runs an update of the failed comics only.
+	RetryFailed(context.Context) error
+	// Reindex normalizes again comics stored with an outdated normalization version.
+	Reindex(context.Context) error
 	Drop(context.Context) error
 }
 
 type Searcher interface {
-	Search(context.Context, string, int) ([]Comics, error)
-	SearchIndex(context.Context, string, int) ([]Comics, error)
+	Search(context.Context, SearchRequest) (SearchResult, error)
+	SearchIndex(context.Context, SearchRequest) (SearchResult, error)
+	Get(context.Context, int) (ComicsInfo, error)
+	List(context.Context, ListRequest) (ListResult, error)
+	Related(ctx context.Context, ID, limit int) (SearchResult, error)
+	Suggest(ctx context.Context, prefix string, limit int) ([]Suggestion, error)
 }
@@ -69,6 +69,8 @@ func run(cfg config.Config, log *slog.Logger) error {
 	mux.Handle("POST /api/login", rest.NewLoginHandler(log, authSrv))
 	mux.Handle("GET /api/db/stats", rest.NewUpdateStatsHandler(log, updateClient))
 	mux.Handle("GET /api/db/status", rest.NewUpdateStatusHandler(log, updateClient))
+	mux.Handle("GET /api/db/status/stream", rest.NewUpdateStatusStreamHandler(log, updateClient))
+	mux.Handle("GET /api/db/failures", rest.NewFailuresHandler(log, updateClient))
 
 	// authorize update/delete
 	mux.Handle("POST /api/db/update",
@@ -76,6 +78,21 @@ func run(cfg config.Config, log *slog.Logger) error {
 			rest.NewUpdateHandler(log, updateClient), authSrv,
 		),
 	)
+	mux.Handle("DELETE /api/db/update",
+		middleware.Auth(
+			rest.NewCancelUpdateHandler(log, updateClient), authSrv,
+		),
+	)
+	mux.Handle("POST /api/db/failures/retry",
+		middleware.Auth(
+			rest.NewRetryFailedHandler(log, updateClient), authSrv,
+		),
+	)
+	mux.Handle("POST /api/db/reindex",
+		middleware.Auth(
+			rest.NewReindexHandler(log, updateClient), authSrv,
+		),
+	)
 	mux.Handle("DELETE /api/db",
 		middleware.Auth(
 			rest.NewDropHandler(log, updateClient), authSrv,
@@ -94,6 +111,29 @@ func run(cfg config.Config, log *slog.Logger) error {
 		),
 	)
 
+	mux.Handle("GET /api/comics",
+		middleware.Rate(
+			rest.NewListHandler(log, searchClient), cfg.SearchRate,
+		),
+	)
+	mux.Handle("GET /api/comics/{id}",
+		middleware.Rate(
+			rest.NewComicsHandler(log, searchClient), cfg.SearchRate,
+		),
+	)
+
+	mux.Handle("GET /api/comics/{id}/related",
+		middleware.Rate(
+			rest.NewRelatedHandler(log, searchClient), cfg.SearchRate,
+		),
+	)
+
+	mux.Handle("GET /api/suggest",
+		middleware.Rate(
+			rest.NewSuggestHandler(log, searchClient), cfg.SearchRate,
+		),
+	)
+
 	mux.Handle("GET /api/ping", rest.NewPingHandler(
 		log,
 		map[string]core.Pinger{
@@ -10,6 +10,7 @@ import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -22,10 +23,76 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type ListOrder int32
+
+const (
+	ListOrder_LIST_ORDER_ID_ASC         ListOrder = 0
+	ListOrder_LIST_ORDER_ID_DESC        ListOrder = 1
+	ListOrder_LIST_ORDER_PUBLISHED_ASC  ListOrder = 2
+	ListOrder_LIST_ORDER_PUBLISHED_DESC ListOrder = 3
+	ListOrder_LIST_ORDER_KEYWORDS_ASC   ListOrder = 4
+	ListOrder_LIST_ORDER_KEYWORDS_DESC  ListOrder = 5
+)
+
+// Enum value maps for ListOrder.
+var (
+	ListOrder_name = map[int32]string{
+		0: "LIST_ORDER_ID_ASC",
+		1: "LIST_ORDER_ID_DESC",
+		2: "LIST_ORDER_PUBLISHED_ASC",
+		3: "LIST_ORDER_PUBLISHED_DESC",
+		4: "LIST_ORDER_KEYWORDS_ASC",
+		5: "LIST_ORDER_KEYWORDS_DESC",
+	}
+	ListOrder_value = map[string]int32{
+		"LIST_ORDER_ID_ASC":         0,
+		"LIST_ORDER_ID_DESC":        1,
+		"LIST_ORDER_PUBLISHED_ASC":  2,
+		"LIST_ORDER_PUBLISHED_DESC": 3,
+		"LIST_ORDER_KEYWORDS_ASC":   4,
+		"LIST_ORDER_KEYWORDS_DESC":  5,
+	}
+)
+
+func (x ListOrder) Enum() *ListOrder {
+	p := new(ListOrder)
+	*p = x
+	return p
+}
+
+func (x ListOrder) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ListOrder) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_search_search_proto_enumTypes[0].Descriptor()
+}
+
+func (ListOrder) Type() protoreflect.EnumType {
+	return &file_proto_search_search_proto_enumTypes[0]
+}
+
+func (x ListOrder) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ListOrder.Descriptor instead.
+func (ListOrder) EnumDescriptor() ([]byte, []int) {
+	return file_proto_search_search_proto_rawDescGZIP(), []int{0}
+}
+
 type SearchRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Phrase        string                 `protobuf:"bytes,1,opt,name=phrase,proto3" json:"phrase,omitempty"`
 	Limit         int64                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	Fuzzy         bool                   `protobuf:"varint,3,opt,name=fuzzy,proto3" json:"fuzzy,omitempty"`
+	Offset        int64                  `protobuf:"varint,4,opt,name=offset,proto3" json:"offset,omitempty"`
+	PageToken     string                 `protobuf:"bytes,5,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	From          *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=from,proto3" json:"from,omitempty"`
+	To            *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=to,proto3" json:"to,omitempty"`
+	MinId         int64                  `protobuf:"varint,8,opt,name=min_id,json=minId,proto3" json:"min_id,omitempty"`
+	MaxId         int64                  `protobuf:"varint,9,opt,name=max_id,json=maxId,proto3" json:"max_id,omitempty"`
+	AutoCorrect   bool                   `protobuf:"varint,10,opt,name=auto_correct,json=autoCorrect,proto3" json:"auto_correct,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -74,11 +141,68 @@ func (x *SearchRequest) GetLimit() int64 {
 	return 0
 }
 
+func (x *SearchRequest) GetFuzzy() bool {
+	if x != nil {
+		return x.Fuzzy
+	}
+	return false
+}
+
+func (x *SearchRequest) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *SearchRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *SearchRequest) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+func (x *SearchRequest) GetMinId() int64 {
+	if x != nil {
+		return x.MinId
+	}
+	return 0
+}
+
+func (x *SearchRequest) GetMaxId() int64 {
+	if x != nil {
+		return x.MaxId
+	}
+	return 0
+}
+
+func (x *SearchRequest) GetAutoCorrect() bool {
+	if x != nil {
+		return x.AutoCorrect
+	}
+	return false
+}
+
 type Comics struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
 	Url           string                 `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
-	Score         int64                  `protobuf:"varint,3,opt,name=score,proto3" json:"score,omitempty"`
+	Score         float64                `protobuf:"fixed64,4,opt,name=score,proto3" json:"score,omitempty"`
+	Snippet       string                 `protobuf:"bytes,5,opt,name=snippet,proto3" json:"snippet,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -127,16 +251,27 @@ func (x *Comics) GetUrl() string {
 	return ""
 }
 
-func (x *Comics) GetScore() int64 {
+func (x *Comics) GetScore() float64 {
 	if x != nil {
 		return x.Score
 	}
 	return 0
 }
 
+func (x *Comics) GetSnippet() string {
+	if x != nil {
+		return x.Snippet
+	}
+	return ""
+}
+
 type SearchReply struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Comics        []*Comics              `protobuf:"bytes,1,rep,name=comics,proto3" json:"comics,omitempty"`
+	Total         int64                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	NextPageToken string                 `protobuf:"bytes,3,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	Suggestion    string                 `protobuf:"bytes,4,opt,name=suggestion,proto3" json:"suggestion,omitempty"`
+	Corrected     bool                   `protobuf:"varint,5,opt,name=corrected,proto3" json:"corrected,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -178,24 +313,580 @@ func (x *SearchReply) GetComics() []*Comics {
 	return nil
 }
 
+func (x *SearchReply) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *SearchReply) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+func (x *SearchReply) GetSuggestion() string {
+	if x != nil {
+		return x.Suggestion
+	}
+	return ""
+}
+
+func (x *SearchReply) GetCorrected() bool {
+	if x != nil {
+		return x.Corrected
+	}
+	return false
+}
+
+type GetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRequest) Reset() {
+	*x = GetRequest{}
+	mi := &file_proto_search_search_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRequest) ProtoMessage() {}
+
+func (x *GetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_search_search_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRequest.ProtoReflect.Descriptor instead.
+func (*GetRequest) Descriptor() ([]byte, []int) {
+	return file_proto_search_search_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type ComicsInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Url           string                 `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	Title         string                 `protobuf:"bytes,3,opt,name=title,proto3" json:"title,omitempty"`
+	SafeTitle     string                 `protobuf:"bytes,4,opt,name=safe_title,json=safeTitle,proto3" json:"safe_title,omitempty"`
+	Alt           string                 `protobuf:"bytes,5,opt,name=alt,proto3" json:"alt,omitempty"`
+	Transcript    string                 `protobuf:"bytes,6,opt,name=transcript,proto3" json:"transcript,omitempty"`
+	Published     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=published,proto3" json:"published,omitempty"`
+	Permalink     string                 `protobuf:"bytes,8,opt,name=permalink,proto3" json:"permalink,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ComicsInfo) Reset() {
+	*x = ComicsInfo{}
+	mi := &file_proto_search_search_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ComicsInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ComicsInfo) ProtoMessage() {}
+
+func (x *ComicsInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_search_search_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ComicsInfo.ProtoReflect.Descriptor instead.
+func (*ComicsInfo) Descriptor() ([]byte, []int) {
+	return file_proto_search_search_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ComicsInfo) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *ComicsInfo) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *ComicsInfo) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *ComicsInfo) GetSafeTitle() string {
+	if x != nil {
+		return x.SafeTitle
+	}
+	return ""
+}
+
+func (x *ComicsInfo) GetAlt() string {
+	if x != nil {
+		return x.Alt
+	}
+	return ""
+}
+
+func (x *ComicsInfo) GetTranscript() string {
+	if x != nil {
+		return x.Transcript
+	}
+	return ""
+}
+
+func (x *ComicsInfo) GetPublished() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Published
+	}
+	return nil
+}
+
+func (x *ComicsInfo) GetPermalink() string {
+	if x != nil {
+		return x.Permalink
+	}
+	return ""
+}
+
+type ListRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Limit         int64                  `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset        int64                  `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
+	Order         ListOrder              `protobuf:"varint,3,opt,name=order,proto3,enum=search.ListOrder" json:"order,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRequest) Reset() {
+	*x = ListRequest{}
+	mi := &file_proto_search_search_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRequest) ProtoMessage() {}
+
+func (x *ListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_search_search_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRequest.ProtoReflect.Descriptor instead.
+func (*ListRequest) Descriptor() ([]byte, []int) {
+	return file_proto_search_search_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListRequest) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListRequest) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *ListRequest) GetOrder() ListOrder {
+	if x != nil {
+		return x.Order
+	}
+	return ListOrder_LIST_ORDER_ID_ASC
+}
+
+type ListReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Comics        []*ComicsInfo          `protobuf:"bytes,1,rep,name=comics,proto3" json:"comics,omitempty"`
+	Total         int64                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListReply) Reset() {
+	*x = ListReply{}
+	mi := &file_proto_search_search_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListReply) ProtoMessage() {}
+
+func (x *ListReply) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_search_search_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListReply.ProtoReflect.Descriptor instead.
+func (*ListReply) Descriptor() ([]byte, []int) {
+	return file_proto_search_search_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListReply) GetComics() []*ComicsInfo {
+	if x != nil {
+		return x.Comics
+	}
+	return nil
+}
+
+func (x *ListReply) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+type RelatedRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Limit         int64                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RelatedRequest) Reset() {
+	*x = RelatedRequest{}
+	mi := &file_proto_search_search_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RelatedRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RelatedRequest) ProtoMessage() {}
+
+func (x *RelatedRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_search_search_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RelatedRequest.ProtoReflect.Descriptor instead.
+func (*RelatedRequest) Descriptor() ([]byte, []int) {
+	return file_proto_search_search_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *RelatedRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *RelatedRequest) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type SuggestRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Prefix        string                 `protobuf:"bytes,1,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	Limit         int64                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SuggestRequest) Reset() {
+	*x = SuggestRequest{}
+	mi := &file_proto_search_search_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SuggestRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SuggestRequest) ProtoMessage() {}
+
+func (x *SuggestRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_search_search_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SuggestRequest.ProtoReflect.Descriptor instead.
+func (*SuggestRequest) Descriptor() ([]byte, []int) {
+	return file_proto_search_search_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *SuggestRequest) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+func (x *SuggestRequest) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type Suggestion struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Text          string                 `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	Keyword       string                 `protobuf:"bytes,2,opt,name=keyword,proto3" json:"keyword,omitempty"`
+	Frequency     int64                  `protobuf:"varint,3,opt,name=frequency,proto3" json:"frequency,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Suggestion) Reset() {
+	*x = Suggestion{}
+	mi := &file_proto_search_search_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Suggestion) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Suggestion) ProtoMessage() {}
+
+func (x *Suggestion) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_search_search_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Suggestion.ProtoReflect.Descriptor instead.
+func (*Suggestion) Descriptor() ([]byte, []int) {
+	return file_proto_search_search_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Suggestion) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *Suggestion) GetKeyword() string {
+	if x != nil {
+		return x.Keyword
+	}
+	return ""
+}
+
+func (x *Suggestion) GetFrequency() int64 {
+	if x != nil {
+		return x.Frequency
+	}
+	return 0
+}
+
+type SuggestReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Suggestions   []*Suggestion          `protobuf:"bytes,1,rep,name=suggestions,proto3" json:"suggestions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SuggestReply) Reset() {
+	*x = SuggestReply{}
+	mi := &file_proto_search_search_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SuggestReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SuggestReply) ProtoMessage() {}
+
+func (x *SuggestReply) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_search_search_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SuggestReply.ProtoReflect.Descriptor instead.
+func (*SuggestReply) Descriptor() ([]byte, []int) {
+	return file_proto_search_search_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *SuggestReply) GetSuggestions() []*Suggestion {
+	if x != nil {
+		return x.Suggestions
+	}
+	return nil
+}
+
 var File_proto_search_search_proto protoreflect.FileDescriptor
 
 const file_proto_search_search_proto_rawDesc = "" +
 	"\n" +
-	"\x19proto/search/search.proto\x12\x06search\x1a\x1bgoogle/protobuf/empty.proto\"=\n" +
+	"\x19proto/search/search.proto\x12\x06search\x1a\x1bgoogle/protobuf/empty.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xb7\x02\n" +
 	"\rSearchRequest\x12\x16\n" +
 	"\x06phrase\x18\x01 \x01(\tR\x06phrase\x12\x14\n" +
-	"\x05limit\x18\x02 \x01(\x03R\x05limit\"@\n" +
+	"\x05limit\x18\x02 \x01(\x03R\x05limit\x12\x14\n" +
+	"\x05fuzzy\x18\x03 \x01(\bR\x05fuzzy\x12\x16\n" +
+	"\x06offset\x18\x04 \x01(\x03R\x06offset\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x05 \x01(\tR\tpageToken\x12.\n" +
+	"\x04from\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12*\n" +
+	"\x02to\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\x02to\x12\x15\n" +
+	"\x06min_id\x18\b \x01(\x03R\x05minId\x12\x15\n" +
+	"\x06max_id\x18\t \x01(\x03R\x05maxId\x12!\n" +
+	"\fauto_correct\x18\n" +
+	" \x01(\bR\vautoCorrect\"`\n" +
 	"\x06Comics\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x10\n" +
 	"\x03url\x18\x02 \x01(\tR\x03url\x12\x14\n" +
-	"\x05score\x18\x03 \x01(\x03R\x05score\"5\n" +
+	"\x05score\x18\x04 \x01(\x01R\x05score\x12\x18\n" +
+	"\asnippet\x18\x05 \x01(\tR\asnippetJ\x04\b\x03\x10\x04\"\xb1\x01\n" +
 	"\vSearchReply\x12&\n" +
-	"\x06comics\x18\x01 \x03(\v2\x0e.search.ComicsR\x06comics2\xb7\x01\n" +
+	"\x06comics\x18\x01 \x03(\v2\x0e.search.ComicsR\x06comics\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x03R\x05total\x12&\n" +
+	"\x0fnext_page_token\x18\x03 \x01(\tR\rnextPageToken\x12\x1e\n" +
+	"\n" +
+	"suggestion\x18\x04 \x01(\tR\n" +
+	"suggestion\x12\x1c\n" +
+	"\tcorrected\x18\x05 \x01(\bR\tcorrected\"\x1c\n" +
+	"\n" +
+	"GetRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"\xed\x01\n" +
+	"\n" +
+	"ComicsInfo\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x10\n" +
+	"\x03url\x18\x02 \x01(\tR\x03url\x12\x14\n" +
+	"\x05title\x18\x03 \x01(\tR\x05title\x12\x1d\n" +
+	"\n" +
+	"safe_title\x18\x04 \x01(\tR\tsafeTitle\x12\x10\n" +
+	"\x03alt\x18\x05 \x01(\tR\x03alt\x12\x1e\n" +
+	"\n" +
+	"transcript\x18\x06 \x01(\tR\n" +
+	"transcript\x128\n" +
+	"\tpublished\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tpublished\x12\x1c\n" +
+	"\tpermalink\x18\b \x01(\tR\tpermalink\"d\n" +
+	"\vListRequest\x12\x14\n" +
+	"\x05limit\x18\x01 \x01(\x03R\x05limit\x12\x16\n" +
+	"\x06offset\x18\x02 \x01(\x03R\x06offset\x12'\n" +
+	"\x05order\x18\x03 \x01(\x0e2\x11.search.ListOrderR\x05order\"M\n" +
+	"\tListReply\x12*\n" +
+	"\x06comics\x18\x01 \x03(\v2\x12.search.ComicsInfoR\x06comics\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x03R\x05total\"6\n" +
+	"\x0eRelatedRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x03R\x05limit\">\n" +
+	"\x0eSuggestRequest\x12\x16\n" +
+	"\x06prefix\x18\x01 \x01(\tR\x06prefix\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x03R\x05limit\"X\n" +
+	"\n" +
+	"Suggestion\x12\x12\n" +
+	"\x04text\x18\x01 \x01(\tR\x04text\x12\x18\n" +
+	"\akeyword\x18\x02 \x01(\tR\akeyword\x12\x1c\n" +
+	"\tfrequency\x18\x03 \x01(\x03R\tfrequency\"D\n" +
+	"\fSuggestReply\x124\n" +
+	"\vsuggestions\x18\x01 \x03(\v2\x12.search.SuggestionR\vsuggestions*\xb2\x01\n" +
+	"\tListOrder\x12\x15\n" +
+	"\x11LIST_ORDER_ID_ASC\x10\x00\x12\x16\n" +
+	"\x12LIST_ORDER_ID_DESC\x10\x01\x12\x1c\n" +
+	"\x18LIST_ORDER_PUBLISHED_ASC\x10\x02\x12\x1d\n" +
+	"\x19LIST_ORDER_PUBLISHED_DESC\x10\x03\x12\x1b\n" +
+	"\x17LIST_ORDER_KEYWORDS_ASC\x10\x04\x12\x1c\n" +
+	"\x18LIST_ORDER_KEYWORDS_DESC\x10\x052\x8f\x03\n" +
 	"\x06Search\x128\n" +
 	"\x04Ping\x12\x16.google.protobuf.Empty\x1a\x16.google.protobuf.Empty\"\x00\x126\n" +
 	"\x06Search\x12\x15.search.SearchRequest\x1a\x13.search.SearchReply\"\x00\x12;\n" +
-	"\vSearchIndex\x12\x15.search.SearchRequest\x1a\x13.search.SearchReply\"\x00B\x1fZ\x1dyadro.com/course/proto/searchb\x06proto3"
+	"\vSearchIndex\x12\x15.search.SearchRequest\x1a\x13.search.SearchReply\"\x00\x12/\n" +
+	"\x03Get\x12\x12.search.GetRequest\x1a\x12.search.ComicsInfo\"\x00\x120\n" +
+	"\x04List\x12\x13.search.ListRequest\x1a\x11.search.ListReply\"\x00\x128\n" +
+	"\aRelated\x12\x16.search.RelatedRequest\x1a\x13.search.SearchReply\"\x00\x129\n" +
+	"\aSuggest\x12\x16.search.SuggestRequest\x1a\x14.search.SuggestReply\"\x00B\x1fZ\x1dyadro.com/course/proto/searchb\x06proto3"
 
 var (
 	file_proto_search_search_proto_rawDescOnce sync.Once
@@ -209,26 +900,51 @@ func file_proto_search_search_proto_rawDescGZIP() []byte {
 	return file_proto_search_search_proto_rawDescData
 }
 
-var file_proto_search_search_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_proto_search_search_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_proto_search_search_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
 var file_proto_search_search_proto_goTypes = []any{
-	(*SearchRequest)(nil), // 0: search.SearchRequest
-	(*Comics)(nil),        // 1: search.Comics
-	(*SearchReply)(nil),   // 2: search.SearchReply
-	(*emptypb.Empty)(nil), // 3: google.protobuf.Empty
+	(ListOrder)(0),                // 0: search.ListOrder
+	(*SearchRequest)(nil),         // 1: search.SearchRequest
+	(*Comics)(nil),                // 2: search.Comics
+	(*SearchReply)(nil),           // 3: search.SearchReply
+	(*GetRequest)(nil),            // 4: search.GetRequest
+	(*ComicsInfo)(nil),            // 5: search.ComicsInfo
+	(*ListRequest)(nil),           // 6: search.ListRequest
+	(*ListReply)(nil),             // 7: search.ListReply
+	(*RelatedRequest)(nil),        // 8: search.RelatedRequest
+	(*SuggestRequest)(nil),        // 9: search.SuggestRequest
+	(*Suggestion)(nil),            // 10: search.Suggestion
+	(*SuggestReply)(nil),          // 11: search.SuggestReply
+	(*timestamppb.Timestamp)(nil), // 12: google.protobuf.Timestamp
+	(*emptypb.Empty)(nil),         // 13: google.protobuf.Empty
 }
 var file_proto_search_search_proto_depIdxs = []int32{
-	1, // 0: search.SearchReply.comics:type_name -> search.Comics
-	3, // 1: search.Search.Ping:input_type -> google.protobuf.Empty
-	0, // 2: search.Search.Search:input_type -> search.SearchRequest
-	0, // 3: search.Search.SearchIndex:input_type -> search.SearchRequest
-	3, // 4: search.Search.Ping:output_type -> google.protobuf.Empty
-	2, // 5: search.Search.Search:output_type -> search.SearchReply
-	2, // 6: search.Search.SearchIndex:output_type -> search.SearchReply
-	4, // [4:7] is the sub-list for method output_type
-	1, // [1:4] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	12, // 0: search.SearchRequest.from:type_name -> google.protobuf.Timestamp
+	12, // 1: search.SearchRequest.to:type_name -> google.protobuf.Timestamp
+	2,  // 2: search.SearchReply.comics:type_name -> search.Comics
+	12, // 3: search.ComicsInfo.published:type_name -> google.protobuf.Timestamp
+	0,  // 4: search.ListRequest.order:type_name -> search.ListOrder
+	5,  // 5: search.ListReply.comics:type_name -> search.ComicsInfo
+	10, // 6: search.SuggestReply.suggestions:type_name -> search.Suggestion
+	13, // 7: search.Search.Ping:input_type -> google.protobuf.Empty
+	1,  // 8: search.Search.Search:input_type -> search.SearchRequest
+	1,  // 9: search.Search.SearchIndex:input_type -> search.SearchRequest
+	4,  // 10: search.Search.Get:input_type -> search.GetRequest
+	6,  // 11: search.Search.List:input_type -> search.ListRequest
+	8,  // 12: search.Search.Related:input_type -> search.RelatedRequest
+	9,  // 13: search.Search.Suggest:input_type -> search.SuggestRequest
+	13, // 14: search.Search.Ping:output_type -> google.protobuf.Empty
+	3,  // 15: search.Search.Search:output_type -> search.SearchReply
+	3,  // 16: search.Search.SearchIndex:output_type -> search.SearchReply
+	5,  // 17: search.Search.Get:output_type -> search.ComicsInfo
+	7,  // 18: search.Search.List:output_type -> search.ListReply
+	3,  // 19: search.Search.Related:output_type -> search.SearchReply
+	11, // 20: search.Search.Suggest:output_type -> search.SuggestReply
+	14, // [14:21] is the sub-list for method output_type
+	7,  // [7:14] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
 }
 
 func init() { file_proto_search_search_proto_init() }
@@ -241,13 +957,14 @@ func file_proto_search_search_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_search_search_proto_rawDesc), len(file_proto_search_search_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   3,
+			NumEnums:      1,
+			NumMessages:   11,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_proto_search_search_proto_goTypes,
 		DependencyIndexes: file_proto_search_search_proto_depIdxs,
+		EnumInfos:         file_proto_search_search_proto_enumTypes,
 		MessageInfos:      file_proto_search_search_proto_msgTypes,
 	}.Build()
 	File_proto_search_search_proto = out.File
@@ -23,6 +23,10 @@ const (
 	Search_Ping_FullMethodName        = "/search.Search/Ping"
 	Search_Search_FullMethodName      = "/search.Search/Search"
 	Search_SearchIndex_FullMethodName = "/search.Search/SearchIndex"
+	Search_Get_FullMethodName         = "/search.Search/Get"
+	Search_List_FullMethodName        = "/search.Search/List"
+	Search_Related_FullMethodName     = "/search.Search/Related"
+	Search_Suggest_FullMethodName     = "/search.Search/Suggest"
 )
 
 // SearchClient is the client API for Search service.
@@ -32,6 +36,10 @@ type SearchClient interface {
 	Ping(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchReply, error)
 	SearchIndex(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchReply, error)
+	Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*ComicsInfo, error)
+	List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListReply, error)
+	Related(ctx context.Context, in *RelatedRequest, opts ...grpc.CallOption) (*SearchReply, error)
+	Suggest(ctx context.Context, in *SuggestRequest, opts ...grpc.CallOption) (*SuggestReply, error)
 }
 
 type searchClient struct {
@@ -72,6 +80,46 @@ func (c *searchClient) SearchIndex(ctx context.Context, in *SearchRequest, opts
 	return out, nil
 }
 
+func (c *searchClient) Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*ComicsInfo, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ComicsInfo)
+	err := c.cc.Invoke(ctx, Search_Get_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *searchClient) List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListReply)
+	err := c.cc.Invoke(ctx, Search_List_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *searchClient) Related(ctx context.Context, in *RelatedRequest, opts ...grpc.CallOption) (*SearchReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchReply)
+	err := c.cc.Invoke(ctx, Search_Related_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *searchClient) Suggest(ctx context.Context, in *SuggestRequest, opts ...grpc.CallOption) (*SuggestReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SuggestReply)
+	err := c.cc.Invoke(ctx, Search_Suggest_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // SearchServer is the server API for Search service.
 // All implementations must embed UnimplementedSearchServer
 // for forward compatibility.
@@ -79,6 +127,10 @@ type SearchServer interface {
 	Ping(context.Context, *emptypb.Empty) (*emptypb.Empty, error)
 	Search(context.Context, *SearchRequest) (*SearchReply, error)
 	SearchIndex(context.Context, *SearchRequest) (*SearchReply, error)
+	Get(context.Context, *GetRequest) (*ComicsInfo, error)
+	List(context.Context, *ListRequest) (*ListReply, error)
+	Related(context.Context, *RelatedRequest) (*SearchReply, error)
+	Suggest(context.Context, *SuggestRequest) (*SuggestReply, error)
 	mustEmbedUnimplementedSearchServer()
 }
 
@@ -98,6 +150,18 @@ func (UnimplementedSearchServer) Search(context.Context, *SearchRequest) (*Searc
 func (UnimplementedSearchServer) SearchIndex(context.Context, *SearchRequest) (*SearchReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SearchIndex not implemented")
 }
+func (UnimplementedSearchServer) Get(context.Context, *GetRequest) (*ComicsInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
+}
+func (UnimplementedSearchServer) List(context.Context, *ListRequest) (*ListReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method List not implemented")
+}
+func (UnimplementedSearchServer) Related(context.Context, *RelatedRequest) (*SearchReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Related not implemented")
+}
+func (UnimplementedSearchServer) Suggest(context.Context, *SuggestRequest) (*SuggestReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Suggest not implemented")
+}
 func (UnimplementedSearchServer) mustEmbedUnimplementedSearchServer() {}
 func (UnimplementedSearchServer) testEmbeddedByValue()                {}
 
@@ -173,6 +237,78 @@ func _Search_SearchIndex_Handler(srv interface{}, ctx context.Context, dec func(
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Search_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SearchServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Search_Get_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SearchServer).Get(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Search_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SearchServer).List(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Search_List_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SearchServer).List(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Search_Related_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RelatedRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SearchServer).Related(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Search_Related_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SearchServer).Related(ctx, req.(*RelatedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Search_Suggest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SuggestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SearchServer).Suggest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Search_Suggest_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SearchServer).Suggest(ctx, req.(*SuggestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Search_ServiceDesc is the grpc.ServiceDesc for Search service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -192,6 +328,22 @@ var Search_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "SearchIndex",
 			Handler:    _Search_SearchIndex_Handler,
 		},
+		{
+			MethodName: "Get",
+			Handler:    _Search_Get_Handler,
+		},
+		{
+			MethodName: "List",
+			Handler:    _Search_List_Handler,
+		},
+		{
+			MethodName: "Related",
+			Handler:    _Search_Related_Handler,
+		},
+		{
+			MethodName: "Suggest",
+			Handler:    _Search_Suggest_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/search/search.proto",
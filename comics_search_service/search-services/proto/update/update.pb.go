@@ -9,7 +9,9 @@ package update
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
 	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -139,16 +141,133 @@ func (x *StatsReply) GetComicsFetched() int64 {
 	return 0
 }
 
+// Progress of the running or the last finished update
+type Progress struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Started *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=started,proto3" json:"started,omitempty"`
+	// absent while running
+	Finished   *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=finished,proto3" json:"finished,omitempty"`
+	Planned    int64                  `protobuf:"varint,3,opt,name=planned,proto3" json:"planned,omitempty"`
+	Fetched    int64                  `protobuf:"varint,4,opt,name=fetched,proto3" json:"fetched,omitempty"`
+	Normalized int64                  `protobuf:"varint,5,opt,name=normalized,proto3" json:"normalized,omitempty"`
+	Stored     int64                  `protobuf:"varint,6,opt,name=stored,proto3" json:"stored,omitempty"`
+	Failed     int64                  `protobuf:"varint,7,opt,name=failed,proto3" json:"failed,omitempty"`
+	// absent if unknown
+	Eta *durationpb.Duration `protobuf:"bytes,8,opt,name=eta,proto3" json:"eta,omitempty"`
+	// the update is stopped by Cancel
+	Canceled      bool `protobuf:"varint,9,opt,name=canceled,proto3" json:"canceled,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Progress) Reset() {
+	*x = Progress{}
+	mi := &file_proto_update_update_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Progress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Progress) ProtoMessage() {}
+
+func (x *Progress) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_update_update_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Progress.ProtoReflect.Descriptor instead.
+func (*Progress) Descriptor() ([]byte, []int) {
+	return file_proto_update_update_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Progress) GetStarted() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Started
+	}
+	return nil
+}
+
+func (x *Progress) GetFinished() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Finished
+	}
+	return nil
+}
+
+func (x *Progress) GetPlanned() int64 {
+	if x != nil {
+		return x.Planned
+	}
+	return 0
+}
+
+func (x *Progress) GetFetched() int64 {
+	if x != nil {
+		return x.Fetched
+	}
+	return 0
+}
+
+func (x *Progress) GetNormalized() int64 {
+	if x != nil {
+		return x.Normalized
+	}
+	return 0
+}
+
+func (x *Progress) GetStored() int64 {
+	if x != nil {
+		return x.Stored
+	}
+	return 0
+}
+
+func (x *Progress) GetFailed() int64 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+func (x *Progress) GetEta() *durationpb.Duration {
+	if x != nil {
+		return x.Eta
+	}
+	return nil
+}
+
+func (x *Progress) GetCanceled() bool {
+	if x != nil {
+		return x.Canceled
+	}
+	return false
+}
+
 type StatusReply struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Status        Status                 `protobuf:"varint,1,opt,name=status,proto3,enum=update.Status" json:"status,omitempty"`
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Status Status                 `protobuf:"varint,1,opt,name=status,proto3,enum=update.Status" json:"status,omitempty"`
+	// scheduled checks for new comics, absent if there were none or they are disabled
+	LastRun *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=last_run,json=lastRun,proto3" json:"last_run,omitempty"`
+	NextRun *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=next_run,json=nextRun,proto3" json:"next_run,omitempty"`
+	// absent if there were no updates
+	Progress      *Progress `protobuf:"bytes,4,opt,name=progress,proto3" json:"progress,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *StatusReply) Reset() {
 	*x = StatusReply{}
-	mi := &file_proto_update_update_proto_msgTypes[1]
+	mi := &file_proto_update_update_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -160,7 +279,7 @@ func (x *StatusReply) String() string {
 func (*StatusReply) ProtoMessage() {}
 
 func (x *StatusReply) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_update_update_proto_msgTypes[1]
+	mi := &file_proto_update_update_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -173,7 +292,7 @@ func (x *StatusReply) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StatusReply.ProtoReflect.Descriptor instead.
 func (*StatusReply) Descriptor() ([]byte, []int) {
-	return file_proto_update_update_proto_rawDescGZIP(), []int{1}
+	return file_proto_update_update_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *StatusReply) GetStatus() Status {
@@ -183,29 +302,201 @@ func (x *StatusReply) GetStatus() Status {
 	return Status_STATUS_UNSPECIFIED
 }
 
+func (x *StatusReply) GetLastRun() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastRun
+	}
+	return nil
+}
+
+func (x *StatusReply) GetNextRun() *timestamppb.Timestamp {
+	if x != nil {
+		return x.NextRun
+	}
+	return nil
+}
+
+func (x *StatusReply) GetProgress() *Progress {
+	if x != nil {
+		return x.Progress
+	}
+	return nil
+}
+
+// Comics failed at some stage of the last update it took part in
+type Failure struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// fetch, normalize or store
+	Stage string `protobuf:"bytes,2,opt,name=stage,proto3" json:"stage,omitempty"`
+	Error string `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	// made in all updates since the comics first failed
+	Attempts      int64                  `protobuf:"varint,4,opt,name=attempts,proto3" json:"attempts,omitempty"`
+	Updated       *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=updated,proto3" json:"updated,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Failure) Reset() {
+	*x = Failure{}
+	mi := &file_proto_update_update_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Failure) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Failure) ProtoMessage() {}
+
+func (x *Failure) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_update_update_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Failure.ProtoReflect.Descriptor instead.
+func (*Failure) Descriptor() ([]byte, []int) {
+	return file_proto_update_update_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Failure) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Failure) GetStage() string {
+	if x != nil {
+		return x.Stage
+	}
+	return ""
+}
+
+func (x *Failure) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *Failure) GetAttempts() int64 {
+	if x != nil {
+		return x.Attempts
+	}
+	return 0
+}
+
+func (x *Failure) GetUpdated() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Updated
+	}
+	return nil
+}
+
+type FailuresReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Failures      []*Failure             `protobuf:"bytes,1,rep,name=failures,proto3" json:"failures,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FailuresReply) Reset() {
+	*x = FailuresReply{}
+	mi := &file_proto_update_update_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FailuresReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FailuresReply) ProtoMessage() {}
+
+func (x *FailuresReply) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_update_update_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FailuresReply.ProtoReflect.Descriptor instead.
+func (*FailuresReply) Descriptor() ([]byte, []int) {
+	return file_proto_update_update_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *FailuresReply) GetFailures() []*Failure {
+	if x != nil {
+		return x.Failures
+	}
+	return nil
+}
+
 var File_proto_update_update_proto protoreflect.FileDescriptor
 
 const file_proto_update_update_proto_rawDesc = "" +
 	"\n" +
-	"\x19proto/update/update.proto\x12\x06update\x1a\x1bgoogle/protobuf/empty.proto\"\x9a\x01\n" +
+	"\x19proto/update/update.proto\x12\x06update\x1a\x1bgoogle/protobuf/empty.proto\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x1egoogle/protobuf/duration.proto\"\x9a\x01\n" +
 	"\n" +
 	"StatsReply\x12\x1f\n" +
 	"\vwords_total\x18\x01 \x01(\x03R\n" +
 	"wordsTotal\x12!\n" +
 	"\fwords_unique\x18\x02 \x01(\x03R\vwordsUnique\x12!\n" +
 	"\fcomics_total\x18\x03 \x01(\x03R\vcomicsTotal\x12%\n" +
-	"\x0ecomics_fetched\x18\x04 \x01(\x03R\rcomicsFetched\"5\n" +
+	"\x0ecomics_fetched\x18\x04 \x01(\x03R\rcomicsFetched\"\xc5\x02\n" +
+	"\bProgress\x124\n" +
+	"\astarted\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\astarted\x126\n" +
+	"\bfinished\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\bfinished\x12\x18\n" +
+	"\aplanned\x18\x03 \x01(\x03R\aplanned\x12\x18\n" +
+	"\afetched\x18\x04 \x01(\x03R\afetched\x12\x1e\n" +
+	"\n" +
+	"normalized\x18\x05 \x01(\x03R\n" +
+	"normalized\x12\x16\n" +
+	"\x06stored\x18\x06 \x01(\x03R\x06stored\x12\x16\n" +
+	"\x06failed\x18\a \x01(\x03R\x06failed\x12+\n" +
+	"\x03eta\x18\b \x01(\v2\x19.google.protobuf.DurationR\x03eta\x12\x1a\n" +
+	"\bcanceled\x18\t \x01(\bR\bcanceled\"\xd1\x01\n" +
 	"\vStatusReply\x12&\n" +
-	"\x06status\x18\x01 \x01(\x0e2\x0e.update.StatusR\x06status*E\n" +
+	"\x06status\x18\x01 \x01(\x0e2\x0e.update.StatusR\x06status\x125\n" +
+	"\blast_run\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\alastRun\x125\n" +
+	"\bnext_run\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\anextRun\x12,\n" +
+	"\bprogress\x18\x04 \x01(\v2\x10.update.ProgressR\bprogress\"\x97\x01\n" +
+	"\aFailure\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x14\n" +
+	"\x05stage\x18\x02 \x01(\tR\x05stage\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\x12\x1a\n" +
+	"\battempts\x18\x04 \x01(\x03R\battempts\x124\n" +
+	"\aupdated\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\aupdated\"<\n" +
+	"\rFailuresReply\x12+\n" +
+	"\bfailures\x18\x01 \x03(\v2\x0f.update.FailureR\bfailures*E\n" +
 	"\x06Status\x12\x16\n" +
 	"\x12STATUS_UNSPECIFIED\x10\x00\x12\x0f\n" +
 	"\vSTATUS_IDLE\x10\x01\x12\x12\n" +
-	"\x0eSTATUS_RUNNING\x10\x022\xa8\x02\n" +
+	"\x0eSTATUS_RUNNING\x10\x022\xd9\x04\n" +
 	"\x06Update\x128\n" +
 	"\x04Ping\x12\x16.google.protobuf.Empty\x1a\x16.google.protobuf.Empty\"\x00\x127\n" +
-	"\x06Status\x12\x16.google.protobuf.Empty\x1a\x13.update.StatusReply\"\x00\x12:\n" +
-	"\x06Update\x12\x16.google.protobuf.Empty\x1a\x16.google.protobuf.Empty\"\x00\x125\n" +
-	"\x05Stats\x12\x16.google.protobuf.Empty\x1a\x12.update.StatsReply\"\x00\x128\n" +
+	"\x06Status\x12\x16.google.protobuf.Empty\x1a\x13.update.StatusReply\"\x00\x12>\n" +
+	"\vWatchStatus\x12\x16.google.protobuf.Empty\x1a\x13.update.StatusReply\"\x000\x01\x12:\n" +
+	"\x06Update\x12\x16.google.protobuf.Empty\x1a\x16.google.protobuf.Empty\"\x00\x124\n" +
+	"\x06Cancel\x12\x16.google.protobuf.Empty\x1a\x10.update.Progress\"\x00\x125\n" +
+	"\x05Stats\x12\x16.google.protobuf.Empty\x1a\x12.update.StatsReply\"\x00\x12;\n" +
+	"\bFailures\x12\x16.google.protobuf.Empty\x1a\x15.update.FailuresReply\"\x00\x12?\n" +
+	"\vRetryFailed\x12\x16.google.protobuf.Empty\x1a\x16.google.protobuf.Empty\"\x00\x12;\n" +
+	"\aReindex\x12\x16.google.protobuf.Empty\x1a\x16.google.protobuf.Empty\"\x00\x128\n" +
 	"\x04Drop\x12\x16.google.protobuf.Empty\x1a\x16.google.protobuf.Empty\"\x00B\x1fZ\x1dyadro.com/course/proto/updateb\x06proto3"
 
 var (
@@ -221,30 +512,53 @@ func file_proto_update_update_proto_rawDescGZIP() []byte {
 }
 
 var file_proto_update_update_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_proto_update_update_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_proto_update_update_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
 var file_proto_update_update_proto_goTypes = []any{
-	(Status)(0),           // 0: update.Status
-	(*StatsReply)(nil),    // 1: update.StatsReply
-	(*StatusReply)(nil),   // 2: update.StatusReply
-	(*emptypb.Empty)(nil), // 3: google.protobuf.Empty
+	(Status)(0),                   // 0: update.Status
+	(*StatsReply)(nil),            // 1: update.StatsReply
+	(*Progress)(nil),              // 2: update.Progress
+	(*StatusReply)(nil),           // 3: update.StatusReply
+	(*Failure)(nil),               // 4: update.Failure
+	(*FailuresReply)(nil),         // 5: update.FailuresReply
+	(*timestamppb.Timestamp)(nil), // 6: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),   // 7: google.protobuf.Duration
+	(*emptypb.Empty)(nil),         // 8: google.protobuf.Empty
 }
 var file_proto_update_update_proto_depIdxs = []int32{
-	0, // 0: update.StatusReply.status:type_name -> update.Status
-	3, // 1: update.Update.Ping:input_type -> google.protobuf.Empty
-	3, // 2: update.Update.Status:input_type -> google.protobuf.Empty
-	3, // 3: update.Update.Update:input_type -> google.protobuf.Empty
-	3, // 4: update.Update.Stats:input_type -> google.protobuf.Empty
-	3, // 5: update.Update.Drop:input_type -> google.protobuf.Empty
-	3, // 6: update.Update.Ping:output_type -> google.protobuf.Empty
-	2, // 7: update.Update.Status:output_type -> update.StatusReply
-	3, // 8: update.Update.Update:output_type -> google.protobuf.Empty
-	1, // 9: update.Update.Stats:output_type -> update.StatsReply
-	3, // 10: update.Update.Drop:output_type -> google.protobuf.Empty
-	6, // [6:11] is the sub-list for method output_type
-	1, // [1:6] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	6,  // 0: update.Progress.started:type_name -> google.protobuf.Timestamp
+	6,  // 1: update.Progress.finished:type_name -> google.protobuf.Timestamp
+	7,  // 2: update.Progress.eta:type_name -> google.protobuf.Duration
+	0,  // 3: update.StatusReply.status:type_name -> update.Status
+	6,  // 4: update.StatusReply.last_run:type_name -> google.protobuf.Timestamp
+	6,  // 5: update.StatusReply.next_run:type_name -> google.protobuf.Timestamp
+	2,  // 6: update.StatusReply.progress:type_name -> update.Progress
+	6,  // 7: update.Failure.updated:type_name -> google.protobuf.Timestamp
+	4,  // 8: update.FailuresReply.failures:type_name -> update.Failure
+	8,  // 9: update.Update.Ping:input_type -> google.protobuf.Empty
+	8,  // 10: update.Update.Status:input_type -> google.protobuf.Empty
+	8,  // 11: update.Update.WatchStatus:input_type -> google.protobuf.Empty
+	8,  // 12: update.Update.Update:input_type -> google.protobuf.Empty
+	8,  // 13: update.Update.Cancel:input_type -> google.protobuf.Empty
+	8,  // 14: update.Update.Stats:input_type -> google.protobuf.Empty
+	8,  // 15: update.Update.Failures:input_type -> google.protobuf.Empty
+	8,  // 16: update.Update.RetryFailed:input_type -> google.protobuf.Empty
+	8,  // 17: update.Update.Reindex:input_type -> google.protobuf.Empty
+	8,  // 18: update.Update.Drop:input_type -> google.protobuf.Empty
+	8,  // 19: update.Update.Ping:output_type -> google.protobuf.Empty
+	3,  // 20: update.Update.Status:output_type -> update.StatusReply
+	3,  // 21: update.Update.WatchStatus:output_type -> update.StatusReply
+	8,  // 22: update.Update.Update:output_type -> google.protobuf.Empty
+	2,  // 23: update.Update.Cancel:output_type -> update.Progress
+	1,  // 24: update.Update.Stats:output_type -> update.StatsReply
+	5,  // 25: update.Update.Failures:output_type -> update.FailuresReply
+	8,  // 26: update.Update.RetryFailed:output_type -> google.protobuf.Empty
+	8,  // 27: update.Update.Reindex:output_type -> google.protobuf.Empty
+	8,  // 28: update.Update.Drop:output_type -> google.protobuf.Empty
+	19, // [19:29] is the sub-list for method output_type
+	9,  // [9:19] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
 }
 
 func init() { file_proto_update_update_proto_init() }
@@ -258,7 +572,7 @@ func file_proto_update_update_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_update_update_proto_rawDesc), len(file_proto_update_update_proto_rawDesc)),
 			NumEnums:      1,
-			NumMessages:   2,
+			NumMessages:   5,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -20,11 +20,16 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	Update_Ping_FullMethodName   = "/update.Update/Ping"
-	Update_Status_FullMethodName = "/update.Update/Status"
-	Update_Update_FullMethodName = "/update.Update/Update"
-	Update_Stats_FullMethodName  = "/update.Update/Stats"
-	Update_Drop_FullMethodName   = "/update.Update/Drop"
+	Update_Ping_FullMethodName        = "/update.Update/Ping"
+	Update_Status_FullMethodName      = "/update.Update/Status"
+	Update_WatchStatus_FullMethodName = "/update.Update/WatchStatus"
+	Update_Update_FullMethodName      = "/update.Update/Update"
+	Update_Cancel_FullMethodName      = "/update.Update/Cancel"
+	Update_Stats_FullMethodName       = "/update.Update/Stats"
+	Update_Failures_FullMethodName    = "/update.Update/Failures"
+	Update_RetryFailed_FullMethodName = "/update.Update/RetryFailed"
+	Update_Reindex_FullMethodName     = "/update.Update/Reindex"
+	Update_Drop_FullMethodName        = "/update.Update/Drop"
 )
 
 // UpdateClient is the client API for Update service.
@@ -33,8 +38,19 @@ const (
 type UpdateClient interface {
 	Ping(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	Status(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*StatusReply, error)
+	// Current status and then every its change
+	WatchStatus(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StatusReply], error)
+	// Runs till the end even if the client leaves, aborted if canceled
 	Update(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// Stops the running update, returns its progress when stopped
+	Cancel(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*Progress, error)
 	Stats(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*StatsReply, error)
+	Failures(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*FailuresReply, error)
+	// Update of the failed comics only
+	RetryFailed(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// Normalizes again comics stored with an outdated normalization version,
+	// runs as an update without fetching from XKCD
+	Reindex(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	Drop(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error)
 }
 
@@ -66,6 +82,25 @@ func (c *updateClient) Status(ctx context.Context, in *emptypb.Empty, opts ...gr
 	return out, nil
 }
 
+func (c *updateClient) WatchStatus(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StatusReply], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Update_ServiceDesc.Streams[0], Update_WatchStatus_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[emptypb.Empty, StatusReply]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Update_WatchStatusClient = grpc.ServerStreamingClient[StatusReply]
+
 func (c *updateClient) Update(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(emptypb.Empty)
@@ -76,6 +111,16 @@ func (c *updateClient) Update(ctx context.Context, in *emptypb.Empty, opts ...gr
 	return out, nil
 }
 
+func (c *updateClient) Cancel(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*Progress, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Progress)
+	err := c.cc.Invoke(ctx, Update_Cancel_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *updateClient) Stats(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*StatsReply, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(StatsReply)
@@ -86,6 +131,36 @@ func (c *updateClient) Stats(ctx context.Context, in *emptypb.Empty, opts ...grp
 	return out, nil
 }
 
+func (c *updateClient) Failures(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*FailuresReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FailuresReply)
+	err := c.cc.Invoke(ctx, Update_Failures_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *updateClient) RetryFailed(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Update_RetryFailed_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *updateClient) Reindex(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Update_Reindex_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *updateClient) Drop(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(emptypb.Empty)
@@ -102,8 +177,19 @@ func (c *updateClient) Drop(ctx context.Context, in *emptypb.Empty, opts ...grpc
 type UpdateServer interface {
 	Ping(context.Context, *emptypb.Empty) (*emptypb.Empty, error)
 	Status(context.Context, *emptypb.Empty) (*StatusReply, error)
+	// Current status and then every its change
+	WatchStatus(*emptypb.Empty, grpc.ServerStreamingServer[StatusReply]) error
+	// Runs till the end even if the client leaves, aborted if canceled
 	Update(context.Context, *emptypb.Empty) (*emptypb.Empty, error)
+	// Stops the running update, returns its progress when stopped
+	Cancel(context.Context, *emptypb.Empty) (*Progress, error)
 	Stats(context.Context, *emptypb.Empty) (*StatsReply, error)
+	Failures(context.Context, *emptypb.Empty) (*FailuresReply, error)
+	// Update of the failed comics only
+	RetryFailed(context.Context, *emptypb.Empty) (*emptypb.Empty, error)
+	// Normalizes again comics stored with an outdated normalization version,
+	// runs as an update without fetching from XKCD
+	Reindex(context.Context, *emptypb.Empty) (*emptypb.Empty, error)
 	Drop(context.Context, *emptypb.Empty) (*emptypb.Empty, error)
 	mustEmbedUnimplementedUpdateServer()
 }
@@ -121,12 +207,27 @@ func (UnimplementedUpdateServer) Ping(context.Context, *emptypb.Empty) (*emptypb
 func (UnimplementedUpdateServer) Status(context.Context, *emptypb.Empty) (*StatusReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Status not implemented")
 }
+func (UnimplementedUpdateServer) WatchStatus(*emptypb.Empty, grpc.ServerStreamingServer[StatusReply]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchStatus not implemented")
+}
 func (UnimplementedUpdateServer) Update(context.Context, *emptypb.Empty) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Update not implemented")
 }
+func (UnimplementedUpdateServer) Cancel(context.Context, *emptypb.Empty) (*Progress, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Cancel not implemented")
+}
 func (UnimplementedUpdateServer) Stats(context.Context, *emptypb.Empty) (*StatsReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Stats not implemented")
 }
+func (UnimplementedUpdateServer) Failures(context.Context, *emptypb.Empty) (*FailuresReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Failures not implemented")
+}
+func (UnimplementedUpdateServer) RetryFailed(context.Context, *emptypb.Empty) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RetryFailed not implemented")
+}
+func (UnimplementedUpdateServer) Reindex(context.Context, *emptypb.Empty) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Reindex not implemented")
+}
 func (UnimplementedUpdateServer) Drop(context.Context, *emptypb.Empty) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Drop not implemented")
 }
@@ -187,6 +288,17 @@ func _Update_Status_Handler(srv interface{}, ctx context.Context, dec func(inter
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Update_WatchStatus_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(emptypb.Empty)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(UpdateServer).WatchStatus(m, &grpc.GenericServerStream[emptypb.Empty, StatusReply]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Update_WatchStatusServer = grpc.ServerStreamingServer[StatusReply]
+
 func _Update_Update_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(emptypb.Empty)
 	if err := dec(in); err != nil {
@@ -205,6 +317,24 @@ func _Update_Update_Handler(srv interface{}, ctx context.Context, dec func(inter
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Update_Cancel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UpdateServer).Cancel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Update_Cancel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UpdateServer).Cancel(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Update_Stats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(emptypb.Empty)
 	if err := dec(in); err != nil {
@@ -223,6 +353,60 @@ func _Update_Stats_Handler(srv interface{}, ctx context.Context, dec func(interf
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Update_Failures_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UpdateServer).Failures(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Update_Failures_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UpdateServer).Failures(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Update_RetryFailed_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UpdateServer).RetryFailed(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Update_RetryFailed_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UpdateServer).RetryFailed(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Update_Reindex_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UpdateServer).Reindex(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Update_Reindex_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UpdateServer).Reindex(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Update_Drop_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(emptypb.Empty)
 	if err := dec(in); err != nil {
@@ -260,15 +444,37 @@ var Update_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Update",
 			Handler:    _Update_Update_Handler,
 		},
+		{
+			MethodName: "Cancel",
+			Handler:    _Update_Cancel_Handler,
+		},
 		{
 			MethodName: "Stats",
 			Handler:    _Update_Stats_Handler,
 		},
+		{
+			MethodName: "Failures",
+			Handler:    _Update_Failures_Handler,
+		},
+		{
+			MethodName: "RetryFailed",
+			Handler:    _Update_RetryFailed_Handler,
+		},
+		{
+			MethodName: "Reindex",
+			Handler:    _Update_Reindex_Handler,
+		},
 		{
 			MethodName: "Drop",
 			Handler:    _Update_Drop_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchStatus",
+			Handler:       _Update_WatchStatus_Handler,
+			ServerStreams: true,
+		},
+	},
 	Metadata: "proto/update/update.proto",
 }
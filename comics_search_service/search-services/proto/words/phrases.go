@@ -0,0 +1,33 @@
+package words
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxPhraseLen is the longest phrase the words service accepts.
+const MaxPhraseLen = 20000
+
+// MaxBatchLen is the total length of phrases sent in a single message.
+const MaxBatchLen = 500000
+
+// SplitPhrase cuts phrase into parts not longer than limit bytes between words.
+func SplitPhrase(phrase string, limit int) []string {
+	var parts []string
+	for len(phrase) > limit {
+		end := limit
+		for end > 0 && !utf8.RuneStart(phrase[end]) {
+			end--
+		}
+		cut := strings.LastIndexFunc(phrase[:end], func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		if cut <= 0 {
+			cut = end // a very long word
+		}
+		parts = append(parts, phrase[:cut])
+		phrase = phrase[cut:]
+	}
+	return append(parts, phrase)
+}
@@ -0,0 +1,32 @@
+package words
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSplitPhrase(t *testing.T) {
+	tests := []struct {
+		name   string
+		phrase string
+		limit  int
+		want   []string
+	}{
+		{name: "short", phrase: "linux kernel", limit: 20, want: []string{"linux kernel"}},
+		{name: "between words", phrase: "linux kernel sources", limit: 14, want: []string{"linux kernel", " sources"}},
+		{name: "long word", phrase: "supercalifragilistic", limit: 8, want: []string{"supercal", "ifragili", "stic"}},
+		{name: "multibyte", phrase: "кошки", limit: 5, want: []string{"ко", "шк", "и"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			parts := SplitPhrase(test.phrase, test.limit)
+			if !slices.Equal(parts, test.want) {
+				t.Errorf("SplitPhrase() = %q, want %q", parts, test.want)
+			}
+			if strings.Join(parts, "") != test.phrase {
+				t.Errorf("parts %q do not make up the phrase", parts)
+			}
+		})
+	}
+}
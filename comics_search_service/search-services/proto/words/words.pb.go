@@ -23,8 +23,12 @@ const (
 )
 
 type WordsRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Phrase        string                 `protobuf:"bytes,1,opt,name=phrase,proto3" json:"phrase,omitempty"`
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Phrase string                 `protobuf:"bytes,1,opt,name=phrase,proto3" json:"phrase,omitempty"`
+	// "en", "ru" or "auto" (default) to detect language of each word by its script
+	Language string `protobuf:"bytes,2,opt,name=language,proto3" json:"language,omitempty"`
+	// keep repeated keywords in order of words instead of unique ones
+	Repeats       bool `protobuf:"varint,3,opt,name=repeats,proto3" json:"repeats,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -66,9 +70,27 @@ func (x *WordsRequest) GetPhrase() string {
 	return ""
 }
 
+func (x *WordsRequest) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *WordsRequest) GetRepeats() bool {
+	if x != nil {
+		return x.Repeats
+	}
+	return false
+}
+
 type WordsReply struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Words         []string               `protobuf:"bytes,1,rep,name=words,proto3" json:"words,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Words []string               `protobuf:"bytes,1,rep,name=words,proto3" json:"words,omitempty"`
+	// language of the most words
+	Language string `protobuf:"bytes,2,opt,name=language,proto3" json:"language,omitempty"`
+	// normalization version the words are got by
+	Version       string `protobuf:"bytes,3,opt,name=version,proto3" json:"version,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -110,19 +132,467 @@ func (x *WordsReply) GetWords() []string {
 	return nil
 }
 
+func (x *WordsReply) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *WordsReply) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+type VersionReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Version       string                 `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VersionReply) Reset() {
+	*x = VersionReply{}
+	mi := &file_proto_words_words_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VersionReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VersionReply) ProtoMessage() {}
+
+func (x *VersionReply) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_words_words_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VersionReply.ProtoReflect.Descriptor instead.
+func (*VersionReply) Descriptor() ([]byte, []int) {
+	return file_proto_words_words_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *VersionReply) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+type WordsBatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Requests      []*WordsRequest        `protobuf:"bytes,1,rep,name=requests,proto3" json:"requests,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WordsBatchRequest) Reset() {
+	*x = WordsBatchRequest{}
+	mi := &file_proto_words_words_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WordsBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WordsBatchRequest) ProtoMessage() {}
+
+func (x *WordsBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_words_words_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WordsBatchRequest.ProtoReflect.Descriptor instead.
+func (*WordsBatchRequest) Descriptor() ([]byte, []int) {
+	return file_proto_words_words_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *WordsBatchRequest) GetRequests() []*WordsRequest {
+	if x != nil {
+		return x.Requests
+	}
+	return nil
+}
+
+type WordsBatchReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Replies       []*WordsReply          `protobuf:"bytes,1,rep,name=replies,proto3" json:"replies,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WordsBatchReply) Reset() {
+	*x = WordsBatchReply{}
+	mi := &file_proto_words_words_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WordsBatchReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WordsBatchReply) ProtoMessage() {}
+
+func (x *WordsBatchReply) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_words_words_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WordsBatchReply.ProtoReflect.Descriptor instead.
+func (*WordsBatchReply) Descriptor() ([]byte, []int) {
+	return file_proto_words_words_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *WordsBatchReply) GetReplies() []*WordsReply {
+	if x != nil {
+		return x.Replies
+	}
+	return nil
+}
+
+type Synonym struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Words         []string               `protobuf:"bytes,1,rep,name=words,proto3" json:"words,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Synonym) Reset() {
+	*x = Synonym{}
+	mi := &file_proto_words_words_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Synonym) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Synonym) ProtoMessage() {}
+
+func (x *Synonym) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_words_words_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Synonym.ProtoReflect.Descriptor instead.
+func (*Synonym) Descriptor() ([]byte, []int) {
+	return file_proto_words_words_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Synonym) GetWords() []string {
+	if x != nil {
+		return x.Words
+	}
+	return nil
+}
+
+type ExpandReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Synonyms      []*Synonym             `protobuf:"bytes,1,rep,name=synonyms,proto3" json:"synonyms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExpandReply) Reset() {
+	*x = ExpandReply{}
+	mi := &file_proto_words_words_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExpandReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExpandReply) ProtoMessage() {}
+
+func (x *ExpandReply) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_words_words_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExpandReply.ProtoReflect.Descriptor instead.
+func (*ExpandReply) Descriptor() ([]byte, []int) {
+	return file_proto_words_words_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ExpandReply) GetSynonyms() []*Synonym {
+	if x != nil {
+		return x.Synonyms
+	}
+	return nil
+}
+
+type Token struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Text  string                 `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	Stem  string                 `protobuf:"bytes,2,opt,name=stem,proto3" json:"stem,omitempty"`
+	// byte offsets in phrase
+	Start         int64 `protobuf:"varint,3,opt,name=start,proto3" json:"start,omitempty"`
+	End           int64 `protobuf:"varint,4,opt,name=end,proto3" json:"end,omitempty"`
+	Position      int64 `protobuf:"varint,5,opt,name=position,proto3" json:"position,omitempty"`
+	Stop          bool  `protobuf:"varint,6,opt,name=stop,proto3" json:"stop,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Token) Reset() {
+	*x = Token{}
+	mi := &file_proto_words_words_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Token) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Token) ProtoMessage() {}
+
+func (x *Token) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_words_words_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Token.ProtoReflect.Descriptor instead.
+func (*Token) Descriptor() ([]byte, []int) {
+	return file_proto_words_words_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Token) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *Token) GetStem() string {
+	if x != nil {
+		return x.Stem
+	}
+	return ""
+}
+
+func (x *Token) GetStart() int64 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *Token) GetEnd() int64 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+func (x *Token) GetPosition() int64 {
+	if x != nil {
+		return x.Position
+	}
+	return 0
+}
+
+func (x *Token) GetStop() bool {
+	if x != nil {
+		return x.Stop
+	}
+	return false
+}
+
+type AnalyzeReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tokens        []*Token               `protobuf:"bytes,1,rep,name=tokens,proto3" json:"tokens,omitempty"`
+	Language      string                 `protobuf:"bytes,2,opt,name=language,proto3" json:"language,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AnalyzeReply) Reset() {
+	*x = AnalyzeReply{}
+	mi := &file_proto_words_words_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnalyzeReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnalyzeReply) ProtoMessage() {}
+
+func (x *AnalyzeReply) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_words_words_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnalyzeReply.ProtoReflect.Descriptor instead.
+func (*AnalyzeReply) Descriptor() ([]byte, []int) {
+	return file_proto_words_words_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *AnalyzeReply) GetTokens() []*Token {
+	if x != nil {
+		return x.Tokens
+	}
+	return nil
+}
+
+func (x *AnalyzeReply) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+type AnalyzeBatchReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Replies       []*AnalyzeReply        `protobuf:"bytes,1,rep,name=replies,proto3" json:"replies,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AnalyzeBatchReply) Reset() {
+	*x = AnalyzeBatchReply{}
+	mi := &file_proto_words_words_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnalyzeBatchReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnalyzeBatchReply) ProtoMessage() {}
+
+func (x *AnalyzeBatchReply) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_words_words_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnalyzeBatchReply.ProtoReflect.Descriptor instead.
+func (*AnalyzeBatchReply) Descriptor() ([]byte, []int) {
+	return file_proto_words_words_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *AnalyzeBatchReply) GetReplies() []*AnalyzeReply {
+	if x != nil {
+		return x.Replies
+	}
+	return nil
+}
+
 var File_proto_words_words_proto protoreflect.FileDescriptor
 
 const file_proto_words_words_proto_rawDesc = "" +
 	"\n" +
-	"\x17proto/words/words.proto\x12\x05words\x1a\x1bgoogle/protobuf/empty.proto\"&\n" +
+	"\x17proto/words/words.proto\x12\x05words\x1a\x1bgoogle/protobuf/empty.proto\"\\\n" +
 	"\fWordsRequest\x12\x16\n" +
-	"\x06phrase\x18\x01 \x01(\tR\x06phrase\"\"\n" +
+	"\x06phrase\x18\x01 \x01(\tR\x06phrase\x12\x1a\n" +
+	"\blanguage\x18\x02 \x01(\tR\blanguage\x12\x18\n" +
+	"\arepeats\x18\x03 \x01(\bR\arepeats\"X\n" +
 	"\n" +
 	"WordsReply\x12\x14\n" +
-	"\x05words\x18\x01 \x03(\tR\x05words2s\n" +
+	"\x05words\x18\x01 \x03(\tR\x05words\x12\x1a\n" +
+	"\blanguage\x18\x02 \x01(\tR\blanguage\x12\x18\n" +
+	"\aversion\x18\x03 \x01(\tR\aversion\"(\n" +
+	"\fVersionReply\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\tR\aversion\"D\n" +
+	"\x11WordsBatchRequest\x12/\n" +
+	"\brequests\x18\x01 \x03(\v2\x13.words.WordsRequestR\brequests\">\n" +
+	"\x0fWordsBatchReply\x12+\n" +
+	"\areplies\x18\x01 \x03(\v2\x11.words.WordsReplyR\areplies\"\x1f\n" +
+	"\aSynonym\x12\x14\n" +
+	"\x05words\x18\x01 \x03(\tR\x05words\"9\n" +
+	"\vExpandReply\x12*\n" +
+	"\bsynonyms\x18\x01 \x03(\v2\x0e.words.SynonymR\bsynonyms\"\x87\x01\n" +
+	"\x05Token\x12\x12\n" +
+	"\x04text\x18\x01 \x01(\tR\x04text\x12\x12\n" +
+	"\x04stem\x18\x02 \x01(\tR\x04stem\x12\x14\n" +
+	"\x05start\x18\x03 \x01(\x03R\x05start\x12\x10\n" +
+	"\x03end\x18\x04 \x01(\x03R\x03end\x12\x1a\n" +
+	"\bposition\x18\x05 \x01(\x03R\bposition\x12\x12\n" +
+	"\x04stop\x18\x06 \x01(\bR\x04stop\"P\n" +
+	"\fAnalyzeReply\x12$\n" +
+	"\x06tokens\x18\x01 \x03(\v2\f.words.TokenR\x06tokens\x12\x1a\n" +
+	"\blanguage\x18\x02 \x01(\tR\blanguage\"B\n" +
+	"\x11AnalyzeBatchReply\x12-\n" +
+	"\areplies\x18\x01 \x03(\v2\x13.words.AnalyzeReplyR\areplies2\xdc\x03\n" +
 	"\x05Words\x128\n" +
 	"\x04Ping\x12\x16.google.protobuf.Empty\x1a\x16.google.protobuf.Empty\"\x00\x120\n" +
-	"\x04Norm\x12\x13.words.WordsRequest\x1a\x11.words.WordsReply\"\x00B\x1eZ\x1cyadro.com/course/proto/wordsb\x06proto3"
+	"\x04Norm\x12\x13.words.WordsRequest\x1a\x11.words.WordsReply\"\x00\x12?\n" +
+	"\tNormBatch\x12\x18.words.WordsBatchRequest\x1a\x16.words.WordsBatchReply\"\x00\x12:\n" +
+	"\n" +
+	"NormStream\x12\x13.words.WordsRequest\x1a\x11.words.WordsReply\"\x00(\x010\x01\x128\n" +
+	"\aVersion\x12\x16.google.protobuf.Empty\x1a\x13.words.VersionReply\"\x00\x123\n" +
+	"\x06Expand\x12\x13.words.WordsRequest\x1a\x12.words.ExpandReply\"\x00\x125\n" +
+	"\aAnalyze\x12\x13.words.WordsRequest\x1a\x13.words.AnalyzeReply\"\x00\x12D\n" +
+	"\fAnalyzeBatch\x12\x18.words.WordsBatchRequest\x1a\x18.words.AnalyzeBatchReply\"\x00B\x1eZ\x1cyadro.com/course/proto/wordsb\x06proto3"
 
 var (
 	file_proto_words_words_proto_rawDescOnce sync.Once
@@ -136,22 +606,47 @@ func file_proto_words_words_proto_rawDescGZIP() []byte {
 	return file_proto_words_words_proto_rawDescData
 }
 
-var file_proto_words_words_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_proto_words_words_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
 var file_proto_words_words_proto_goTypes = []any{
-	(*WordsRequest)(nil),  // 0: words.WordsRequest
-	(*WordsReply)(nil),    // 1: words.WordsReply
-	(*emptypb.Empty)(nil), // 2: google.protobuf.Empty
+	(*WordsRequest)(nil),      // 0: words.WordsRequest
+	(*WordsReply)(nil),        // 1: words.WordsReply
+	(*VersionReply)(nil),      // 2: words.VersionReply
+	(*WordsBatchRequest)(nil), // 3: words.WordsBatchRequest
+	(*WordsBatchReply)(nil),   // 4: words.WordsBatchReply
+	(*Synonym)(nil),           // 5: words.Synonym
+	(*ExpandReply)(nil),       // 6: words.ExpandReply
+	(*Token)(nil),             // 7: words.Token
+	(*AnalyzeReply)(nil),      // 8: words.AnalyzeReply
+	(*AnalyzeBatchReply)(nil), // 9: words.AnalyzeBatchReply
+	(*emptypb.Empty)(nil),     // 10: google.protobuf.Empty
 }
 var file_proto_words_words_proto_depIdxs = []int32{
-	2, // 0: words.Words.Ping:input_type -> google.protobuf.Empty
-	0, // 1: words.Words.Norm:input_type -> words.WordsRequest
-	2, // 2: words.Words.Ping:output_type -> google.protobuf.Empty
-	1, // 3: words.Words.Norm:output_type -> words.WordsReply
-	2, // [2:4] is the sub-list for method output_type
-	0, // [0:2] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	0,  // 0: words.WordsBatchRequest.requests:type_name -> words.WordsRequest
+	1,  // 1: words.WordsBatchReply.replies:type_name -> words.WordsReply
+	5,  // 2: words.ExpandReply.synonyms:type_name -> words.Synonym
+	7,  // 3: words.AnalyzeReply.tokens:type_name -> words.Token
+	8,  // 4: words.AnalyzeBatchReply.replies:type_name -> words.AnalyzeReply
+	10, // 5: words.Words.Ping:input_type -> google.protobuf.Empty
+	0,  // 6: words.Words.Norm:input_type -> words.WordsRequest
+	3,  // 7: words.Words.NormBatch:input_type -> words.WordsBatchRequest
+	0,  // 8: words.Words.NormStream:input_type -> words.WordsRequest
+	10, // 9: words.Words.Version:input_type -> google.protobuf.Empty
+	0,  // 10: words.Words.Expand:input_type -> words.WordsRequest
+	0,  // 11: words.Words.Analyze:input_type -> words.WordsRequest
+	3,  // 12: words.Words.AnalyzeBatch:input_type -> words.WordsBatchRequest
+	10, // 13: words.Words.Ping:output_type -> google.protobuf.Empty
+	1,  // 14: words.Words.Norm:output_type -> words.WordsReply
+	4,  // 15: words.Words.NormBatch:output_type -> words.WordsBatchReply
+	1,  // 16: words.Words.NormStream:output_type -> words.WordsReply
+	2,  // 17: words.Words.Version:output_type -> words.VersionReply
+	6,  // 18: words.Words.Expand:output_type -> words.ExpandReply
+	8,  // 19: words.Words.Analyze:output_type -> words.AnalyzeReply
+	9,  // 20: words.Words.AnalyzeBatch:output_type -> words.AnalyzeBatchReply
+	13, // [13:21] is the sub-list for method output_type
+	5,  // [5:13] is the sub-list for method input_type
+	5,  // [5:5] is the sub-list for extension type_name
+	5,  // [5:5] is the sub-list for extension extendee
+	0,  // [0:5] is the sub-list for field type_name
 }
 
 func init() { file_proto_words_words_proto_init() }
@@ -165,7 +660,7 @@ func file_proto_words_words_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_words_words_proto_rawDesc), len(file_proto_words_words_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   2,
+			NumMessages:   10,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -20,8 +20,14 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	Words_Ping_FullMethodName = "/words.Words/Ping"
-	Words_Norm_FullMethodName = "/words.Words/Norm"
+	Words_Ping_FullMethodName         = "/words.Words/Ping"
+	Words_Norm_FullMethodName         = "/words.Words/Norm"
+	Words_NormBatch_FullMethodName    = "/words.Words/NormBatch"
+	Words_NormStream_FullMethodName   = "/words.Words/NormStream"
+	Words_Version_FullMethodName      = "/words.Words/Version"
+	Words_Expand_FullMethodName       = "/words.Words/Expand"
+	Words_Analyze_FullMethodName      = "/words.Words/Analyze"
+	Words_AnalyzeBatch_FullMethodName = "/words.Words/AnalyzeBatch"
 )
 
 // WordsClient is the client API for Words service.
@@ -33,6 +39,17 @@ type WordsClient interface {
 	Ping(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	// Send name, receive greeting
 	Norm(ctx context.Context, in *WordsRequest, opts ...grpc.CallOption) (*WordsReply, error)
+	// Many phrases at once, each phrase is limited in length, replies go in order of requests
+	NormBatch(ctx context.Context, in *WordsBatchRequest, opts ...grpc.CallOption) (*WordsBatchReply, error)
+	NormStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[WordsRequest, WordsReply], error)
+	// Changes when keywords of the same phrase change
+	Version(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*VersionReply, error)
+	// Synonyms of a phrase as lists of keywords
+	Expand(ctx context.Context, in *WordsRequest, opts ...grpc.CallOption) (*ExpandReply, error)
+	// Every word of a phrase in order with its stem and position
+	Analyze(ctx context.Context, in *WordsRequest, opts ...grpc.CallOption) (*AnalyzeReply, error)
+	// Many phrases at once, each phrase is limited in length, replies go in order of requests
+	AnalyzeBatch(ctx context.Context, in *WordsBatchRequest, opts ...grpc.CallOption) (*AnalyzeBatchReply, error)
 }
 
 type wordsClient struct {
@@ -63,6 +80,69 @@ func (c *wordsClient) Norm(ctx context.Context, in *WordsRequest, opts ...grpc.C
 	return out, nil
 }
 
+func (c *wordsClient) NormBatch(ctx context.Context, in *WordsBatchRequest, opts ...grpc.CallOption) (*WordsBatchReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WordsBatchReply)
+	err := c.cc.Invoke(ctx, Words_NormBatch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *wordsClient) NormStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[WordsRequest, WordsReply], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Words_ServiceDesc.Streams[0], Words_NormStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WordsRequest, WordsReply]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Words_NormStreamClient = grpc.BidiStreamingClient[WordsRequest, WordsReply]
+
+func (c *wordsClient) Version(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*VersionReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VersionReply)
+	err := c.cc.Invoke(ctx, Words_Version_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *wordsClient) Expand(ctx context.Context, in *WordsRequest, opts ...grpc.CallOption) (*ExpandReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExpandReply)
+	err := c.cc.Invoke(ctx, Words_Expand_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *wordsClient) Analyze(ctx context.Context, in *WordsRequest, opts ...grpc.CallOption) (*AnalyzeReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AnalyzeReply)
+	err := c.cc.Invoke(ctx, Words_Analyze_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *wordsClient) AnalyzeBatch(ctx context.Context, in *WordsBatchRequest, opts ...grpc.CallOption) (*AnalyzeBatchReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AnalyzeBatchReply)
+	err := c.cc.Invoke(ctx, Words_AnalyzeBatch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // WordsServer is the server API for Words service.
 // All implementations must embed UnimplementedWordsServer
 // for forward compatibility.
@@ -72,6 +152,17 @@ type WordsServer interface {
 	Ping(context.Context, *emptypb.Empty) (*emptypb.Empty, error)
 	// Send name, receive greeting
 	Norm(context.Context, *WordsRequest) (*WordsReply, error)
+	// Many phrases at once, each phrase is limited in length, replies go in order of requests
+	NormBatch(context.Context, *WordsBatchRequest) (*WordsBatchReply, error)
+	NormStream(grpc.BidiStreamingServer[WordsRequest, WordsReply]) error
+	// Changes when keywords of the same phrase change
+	Version(context.Context, *emptypb.Empty) (*VersionReply, error)
+	// Synonyms of a phrase as lists of keywords
+	Expand(context.Context, *WordsRequest) (*ExpandReply, error)
+	// Every word of a phrase in order with its stem and position
+	Analyze(context.Context, *WordsRequest) (*AnalyzeReply, error)
+	// Many phrases at once, each phrase is limited in length, replies go in order of requests
+	AnalyzeBatch(context.Context, *WordsBatchRequest) (*AnalyzeBatchReply, error)
 	mustEmbedUnimplementedWordsServer()
 }
 
@@ -88,6 +179,24 @@ func (UnimplementedWordsServer) Ping(context.Context, *emptypb.Empty) (*emptypb.
 func (UnimplementedWordsServer) Norm(context.Context, *WordsRequest) (*WordsReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Norm not implemented")
 }
+func (UnimplementedWordsServer) NormBatch(context.Context, *WordsBatchRequest) (*WordsBatchReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method NormBatch not implemented")
+}
+func (UnimplementedWordsServer) NormStream(grpc.BidiStreamingServer[WordsRequest, WordsReply]) error {
+	return status.Errorf(codes.Unimplemented, "method NormStream not implemented")
+}
+func (UnimplementedWordsServer) Version(context.Context, *emptypb.Empty) (*VersionReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Version not implemented")
+}
+func (UnimplementedWordsServer) Expand(context.Context, *WordsRequest) (*ExpandReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Expand not implemented")
+}
+func (UnimplementedWordsServer) Analyze(context.Context, *WordsRequest) (*AnalyzeReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Analyze not implemented")
+}
+func (UnimplementedWordsServer) AnalyzeBatch(context.Context, *WordsBatchRequest) (*AnalyzeBatchReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AnalyzeBatch not implemented")
+}
 func (UnimplementedWordsServer) mustEmbedUnimplementedWordsServer() {}
 func (UnimplementedWordsServer) testEmbeddedByValue()               {}
 
@@ -145,6 +254,103 @@ func _Words_Norm_Handler(srv interface{}, ctx context.Context, dec func(interfac
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Words_NormBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WordsBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WordsServer).NormBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Words_NormBatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WordsServer).NormBatch(ctx, req.(*WordsBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Words_NormStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(WordsServer).NormStream(&grpc.GenericServerStream[WordsRequest, WordsReply]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Words_NormStreamServer = grpc.BidiStreamingServer[WordsRequest, WordsReply]
+
+func _Words_Version_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WordsServer).Version(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Words_Version_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WordsServer).Version(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Words_Expand_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WordsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WordsServer).Expand(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Words_Expand_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WordsServer).Expand(ctx, req.(*WordsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Words_Analyze_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WordsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WordsServer).Analyze(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Words_Analyze_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WordsServer).Analyze(ctx, req.(*WordsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Words_AnalyzeBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WordsBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WordsServer).AnalyzeBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Words_AnalyzeBatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WordsServer).AnalyzeBatch(ctx, req.(*WordsBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Words_ServiceDesc is the grpc.ServiceDesc for Words service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -160,7 +366,34 @@ var Words_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Norm",
 			Handler:    _Words_Norm_Handler,
 		},
+		{
+			MethodName: "NormBatch",
+			Handler:    _Words_NormBatch_Handler,
+		},
+		{
+			MethodName: "Version",
+			Handler:    _Words_Version_Handler,
+		},
+		{
+			MethodName: "Expand",
+			Handler:    _Words_Expand_Handler,
+		},
+		{
+			MethodName: "Analyze",
+			Handler:    _Words_Analyze_Handler,
+		},
+		{
+			MethodName: "AnalyzeBatch",
+			Handler:    _Words_AnalyzeBatch_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "NormStream",
+			Handler:       _Words_NormStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/words/words.proto",
 }
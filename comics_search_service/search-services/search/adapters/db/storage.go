@@ -4,7 +4,11 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"iter"
 	"log/slog"
+	"strings"
+	"time"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/jmoiron/sqlx"
@@ -35,35 +39,229 @@ func (db *DB) Close() error {
 	return db.conn.Close()
 }
 
-func (db *DB) Search(ctx context.Context, keyword string) ([]int, error) {
-	var IDs []int
-	err := db.conn.SelectContext(
-		ctx, &IDs,
-		"SELECT id FROM comics WHERE $1 = ANY(words)",
+type Posting struct {
+	ID        int           `db:"id"`
+	Length    int           `db:"length"`
+	Positions pq.Int64Array `db:"positions"`
+}
+
+// columns keep keywords of fields
+var columns = map[core.Field]string{
+	core.FieldWords:      "words",
+	core.FieldTitle:      "title_words",
+	core.FieldAlt:        "alt_words",
+	core.FieldTranscript: "transcript_words",
+}
+
+func column(field core.Field) (string, error) {
+	name, ok := columns[field]
+	if !ok {
+		return "", fmt.Errorf("%w: unknown field %q", core.ErrBadArguments, field)
+	}
+	return name, nil
+}
+
+func (db *DB) Search(ctx context.Context, field core.Field, keyword string) ([]core.Posting, error) {
+	name, err := column(field)
+	if err != nil {
+		return nil, err
+	}
+	var postings []Posting
+	err = db.conn.SelectContext(
+		ctx, &postings,
+		fmt.Sprintf(
+			`SELECT id, array_positions(%[1]s, $1) AS positions, cardinality(%[1]s) AS length
+			FROM comics WHERE $1 = ANY(%[1]s)`,
+			name,
+		),
 		keyword,
 	)
+	if err != nil {
+		return nil, err
+	}
 
-	return IDs, err
+	result := make([]core.Posting, 0, len(postings))
+	for _, p := range postings {
+		positions := make([]int, 0, len(p.Positions))
+		for _, position := range p.Positions {
+			positions = append(positions, int(position)-1) // SQL arrays start at 1
+		}
+		result = append(result, core.Posting{ID: p.ID, TF: len(positions), Length: p.Length, Positions: positions})
+	}
+	return result, nil
 }
 
+// cursorBatch is the number of comics fetched from a cursor at once.
+const cursorBatch = 500
+
 type Comics struct {
-	ID       int            `db:"id"`
-	URL      string         `db:"url"`
-	Keywords pq.StringArray `db:"words"`
+	ID                 int            `db:"id"`
+	URL                string         `db:"url"`
+	Keywords           pq.StringArray `db:"words"`
+	TitleKeywords      pq.StringArray `db:"title_words"`
+	AltKeywords        pq.StringArray `db:"alt_words"`
+	TranscriptKeywords pq.StringArray `db:"transcript_words"`
+	Title              string         `db:"title"`
+	SafeTitle          string         `db:"safe_title"`
+	Alt                string         `db:"alt"`
+	Transcript         string         `db:"transcript"`
+	Published          sql.NullTime   `db:"published"`
+	Permalink          string         `db:"permalink"`
 }
 
+const comicsColumns = `id, url, words, title_words, alt_words, transcript_words,
+	title, safe_title, alt, transcript, published, permalink`
+
 func (db *DB) Get(ctx context.Context, id int) (core.Comics, error) {
 	var comics Comics
 	err := db.conn.GetContext(
 		ctx, &comics,
-		"SELECT id, url, words FROM comics WHERE id = $1",
+		"SELECT "+comicsColumns+" FROM comics WHERE id = $1",
 		id,
 	)
 	if errors.Is(err, sql.ErrNoRows) {
 		err = core.ErrNotFound
 	}
 
-	return core.Comics{ID: comics.ID, URL: comics.URL, Keywords: comics.Keywords}, err
+	return comics.toCore(), err
+}
+
+func (c Comics) toCore() core.Comics {
+	return core.Comics{
+		ID:  c.ID,
+		URL: c.URL,
+		Keywords: map[core.Field][]string{
+			core.FieldWords:      c.Keywords,
+			core.FieldTitle:      c.TitleKeywords,
+			core.FieldAlt:        c.AltKeywords,
+			core.FieldTranscript: c.TranscriptKeywords,
+		},
+		Title:      c.Title,
+		SafeTitle:  c.SafeTitle,
+		Alt:        c.Alt,
+		Transcript: c.Transcript,
+		Published:  c.Published.Time,
+		Permalink:  c.Permalink,
+	}
+}
+
+var listOrders = map[core.ListOrder]string{
+	core.ListByID:            "id",
+	core.ListByIDDesc:        "id DESC",
+	core.ListByPublished:     "published NULLS LAST, id",
+	core.ListByPublishedDesc: "published DESC NULLS LAST, id",
+	core.ListByKeywords:      "cardinality(words), id",
+	core.ListByKeywordsDesc:  "cardinality(words) DESC, id",
+}
+
+func (db *DB) List(ctx context.Context, req core.ListRequest) ([]core.Comics, error) {
+	order, ok := listOrders[req.Order]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown order %d", core.ErrBadArguments, req.Order)
+	}
+	var comics []Comics
+	err := db.conn.SelectContext(
+		ctx, &comics,
+		"SELECT "+comicsColumns+" FROM comics ORDER BY "+order+" LIMIT $1 OFFSET $2",
+		req.Limit, req.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]core.Comics, 0, len(comics))
+	for _, c := range comics {
+		result = append(result, c.toCore())
+	}
+	return result, nil
+}
+
+// GetMany returns comics in the order of IDs, missing comics are skipped.
+func (db *DB) GetMany(ctx context.Context, IDs []int) ([]core.Comics, error) {
+	var comics []Comics
+	err := db.conn.SelectContext(
+		ctx, &comics,
+		"SELECT "+comicsColumns+" FROM comics WHERE id = ANY($1)",
+		IDs,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int]Comics, len(comics))
+	for _, c := range comics {
+		byID[c.ID] = c
+	}
+	result := make([]core.Comics, 0, len(comics))
+	for _, ID := range IDs {
+		if c, ok := byID[ID]; ok {
+			result = append(result, c.toCore())
+		}
+	}
+	return result, nil
+}
+
+// Published returns publication dates of comics, unknown dates are omitted.
+func (db *DB) Published(ctx context.Context, IDs []int) (map[int]time.Time, error) {
+	var rows []struct {
+		ID        int       `db:"id"`
+		Published time.Time `db:"published"`
+	}
+	err := db.conn.SelectContext(
+		ctx, &rows,
+		"SELECT id, published FROM comics WHERE id = ANY($1) AND published IS NOT NULL",
+		IDs,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[int]time.Time, len(rows))
+	for _, row := range rows {
+		result[row.ID] = row.Published
+	}
+	return result, nil
+}
+
+// All streams comics ordered by ID through a server-side cursor,
+// so that the whole table is never loaded in memory at once.
+func (db *DB) All(ctx context.Context) iter.Seq2[core.Comics, error] {
+	return func(yield func(core.Comics, error) bool) {
+		tx, err := db.conn.BeginTxx(ctx, &sql.TxOptions{ReadOnly: true})
+		if err != nil {
+			yield(core.Comics{}, err)
+			return
+		}
+		defer func() {
+			if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+				db.log.Warn("failed to close cursor transaction", "error", err)
+			}
+		}()
+
+		_, err = tx.ExecContext(
+			ctx,
+			"DECLARE comics_cursor NO SCROLL CURSOR FOR SELECT "+comicsColumns+" FROM comics ORDER BY id",
+		)
+		if err != nil {
+			yield(core.Comics{}, err)
+			return
+		}
+		for {
+			var batch []Comics
+			if err := tx.SelectContext(ctx, &batch, fmt.Sprintf("FETCH %d FROM comics_cursor", cursorBatch)); err != nil {
+				yield(core.Comics{}, err)
+				return
+			}
+			for _, c := range batch {
+				if !yield(c.toCore(), nil) {
+					return
+				}
+			}
+			if len(batch) < cursorBatch {
+				return
+			}
+		}
+	}
 }
 
 func (db *DB) LastID(ctx context.Context) (int, error) {
@@ -75,3 +273,84 @@ func (db *DB) LastID(ctx context.Context) (int, error) {
 
 	return ID, err
 }
+
+func (db *DB) Updated(ctx context.Context) (time.Time, error) {
+	var updated sql.NullTime
+	err := db.conn.GetContext(
+		ctx, &updated,
+		"SELECT max(updated) FROM comics",
+	)
+
+	return updated.Time, err
+}
+
+type CorpusStats struct {
+	Documents           int     `db:"documents"`
+	AvgLength           float64 `db:"avg_length"`
+	AvgTitleLength      float64 `db:"avg_title_length"`
+	AvgAltLength        float64 `db:"avg_alt_length"`
+	AvgTranscriptLength float64 `db:"avg_transcript_length"`
+}
+
+func (db *DB) Stats(ctx context.Context) (map[core.Field]core.CorpusStats, error) {
+	var stats CorpusStats
+	err := db.conn.GetContext(
+		ctx, &stats,
+		`SELECT count(*) AS documents,
+			coalesce(avg(cardinality(words)), 0) AS avg_length,
+			coalesce(avg(cardinality(title_words)), 0) AS avg_title_length,
+			coalesce(avg(cardinality(alt_words)), 0) AS avg_alt_length,
+			coalesce(avg(cardinality(transcript_words)), 0) AS avg_transcript_length
+		FROM comics`,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[core.Field]core.CorpusStats{
+		core.FieldWords:      {Documents: stats.Documents, AvgLength: stats.AvgLength},
+		core.FieldTitle:      {Documents: stats.Documents, AvgLength: stats.AvgTitleLength},
+		core.FieldAlt:        {Documents: stats.Documents, AvgLength: stats.AvgAltLength},
+		core.FieldTranscript: {Documents: stats.Documents, AvgLength: stats.AvgTranscriptLength},
+	}, nil
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`, "*", "%", "?", "_")
+
+func (db *DB) Expand(ctx context.Context, field core.Field, pattern string, limit int) ([]string, error) {
+	name, err := column(field)
+	if err != nil {
+		return nil, err
+	}
+	var keywords []string
+	err = db.conn.SelectContext(
+		ctx, &keywords,
+		fmt.Sprintf(
+			`SELECT keyword FROM comics, unnest(%s) AS keyword
+			WHERE keyword LIKE $1 GROUP BY keyword ORDER BY count(DISTINCT id) DESC, keyword LIMIT $2`,
+			name,
+		),
+		likeEscaper.Replace(pattern), limit,
+	)
+
+	return keywords, err
+}
+
+func (db *DB) Keywords(ctx context.Context, field core.Field, maxLength int) ([]string, error) {
+	name, err := column(field)
+	if err != nil {
+		return nil, err
+	}
+	var keywords []string
+	err = db.conn.SelectContext(
+		ctx, &keywords,
+		fmt.Sprintf(
+			`SELECT DISTINCT keyword FROM comics, unnest(%s) AS keyword
+			WHERE char_length(keyword) <= $1`,
+			name,
+		),
+		maxLength,
+	)
+
+	return keywords, err
+}
@@ -2,6 +2,7 @@ package events
 
 import (
 	"context"
+	"encoding/json"
 	"log/slog"
 	"time"
 
@@ -12,10 +13,27 @@ import (
 const updateTopic = "xkcd.db.updated"
 const indexUpdateTimeout = 5 * time.Second
 
+// indexRebuildTimeout is longer as the index is built from all comics in DB.
+const indexRebuildTimeout = 5 * time.Minute
+
+// event is published by the update service on DB changes.
+// Events of one publisher share the epoch and are numbered sequentially.
+type event struct {
+	Epoch   string `json:"epoch"`
+	Seq     uint64 `json:"seq"`
+	Added   []int  `json:"added,omitempty"`
+	Cleaned bool   `json:"cleaned,omitempty"`
+	// stored comics normalized again
+	Reindexed []int `json:"reindexed,omitempty"`
+}
+
 type Broker struct {
 	connection   *nats.Conn
 	subscription *nats.Subscription
 	log          *slog.Logger
+	// the last received event, handlers of a subscription do not run concurrently
+	epoch string
+	seq   uint64
 }
 
 func New(address string, searcher core.Searcher, log *slog.Logger) (*Broker, error) {
@@ -25,19 +43,65 @@ func New(address string, searcher core.Searcher, log *slog.Logger) (*Broker, err
 	}
 	log.Debug("connected to broker", "address", address)
 
+	b := &Broker{connection: nc, log: log}
 	sub, err := nc.Subscribe(updateTopic, func(msg *nats.Msg) {
 		ctx, cancel := context.WithTimeout(context.Background(), indexUpdateTimeout)
 		defer cancel()
-		log.Debug("db update event", "message", msg.Data)
-		if err := searcher.BuildIndex(ctx); err != nil {
-			log.Error("index re-build failed", "error", err)
-		}
+		b.handle(ctx, searcher, msg.Data)
 	})
 	if err != nil {
 		return nil, err
 	}
+	b.subscription = sub
+
+	return b, nil
+}
+
+// handle applies an event to the index, the index is rebuilt
+// if the event can not be applied or previous events were missed.
+func (b *Broker) handle(ctx context.Context, searcher core.Searcher, data []byte) {
+	b.log.Debug("db update event", "message", data)
 
-	return &Broker{connection: nc, subscription: sub, log: log}, nil
+	var e event
+	if err := json.Unmarshal(data, &e); err != nil {
+		b.log.Warn("undecodable db update event, re-building index", "error", err)
+		b.epoch, b.seq = "", 0
+		b.rebuild(ctx, searcher)
+		return
+	}
+	missed := b.missed(e)
+	b.epoch, b.seq = e.Epoch, e.Seq
+	if missed {
+		b.log.Warn("missed db update events, re-building index", "epoch", e.Epoch, "seq", e.Seq)
+		b.rebuild(ctx, searcher)
+		return
+	}
+	update := core.IndexUpdate{Cleaned: e.Cleaned, Added: e.Added, Reindexed: e.Reindexed}
+	if err := searcher.UpdateIndex(ctx, update); err != nil {
+		b.log.Error("index update failed, re-building index", "error", err)
+		b.rebuild(ctx, searcher)
+	}
+}
+
+// missed reports whether there were events between the last one and e.
+// Nothing is missed before the first event, the index is built from DB on start.
+func (b *Broker) missed(e event) bool {
+	switch {
+	case b.epoch == "":
+		return false
+	case e.Epoch != b.epoch:
+		return e.Seq != 1
+	default:
+		return e.Seq != b.seq+1
+	}
+}
+
+func (b *Broker) rebuild(ctx context.Context, searcher core.Searcher) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), indexRebuildTimeout)
+	defer cancel()
+	if err := searcher.BuildIndex(ctx); err != nil {
+		b.log.Error("index re-build failed", "error", err)
+	}
 }
 
 func (b *Broker) Close() {
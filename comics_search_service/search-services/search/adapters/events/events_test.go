@@ -0,0 +1,133 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"yadro.com/course/search/core"
+)
+
+var errNotSupported = errors.New("not supported by fake")
+
+// fakeSearcher counts index builds and updates only.
+type fakeSearcher struct {
+	builds  int
+	updates []core.IndexUpdate
+	// of the last build
+	deadline time.Time
+	canceled bool
+}
+
+func (*fakeSearcher) Search(context.Context, core.SearchRequest) (core.SearchResult, error) {
+	return core.SearchResult{}, errNotSupported
+}
+
+func (*fakeSearcher) SearchIndex(context.Context, core.SearchRequest) (core.SearchResult, error) {
+	return core.SearchResult{}, errNotSupported
+}
+
+func (*fakeSearcher) Get(context.Context, int) (core.Comics, error) {
+	return core.Comics{}, errNotSupported
+}
+
+func (*fakeSearcher) List(context.Context, core.ListRequest) (core.ListResult, error) {
+	return core.ListResult{}, errNotSupported
+}
+
+func (*fakeSearcher) Related(context.Context, int, int) (core.SearchResult, error) {
+	return core.SearchResult{}, errNotSupported
+}
+
+func (*fakeSearcher) Suggest(context.Context, string, int) ([]core.Suggestion, error) {
+	return nil, errNotSupported
+}
+
+func (s *fakeSearcher) BuildIndex(ctx context.Context) error {
+	s.builds++
+	s.deadline, _ = ctx.Deadline()
+	s.canceled = ctx.Err() != nil
+	return nil
+}
+
+func (*fakeSearcher) RestoreIndex(context.Context) error {
+	return errNotSupported
+}
+
+func (s *fakeSearcher) UpdateIndex(_ context.Context, update core.IndexUpdate) error {
+	s.updates = append(s.updates, update)
+	return nil
+}
+
+func TestMissed(t *testing.T) {
+	tests := []struct {
+		name  string
+		epoch string
+		seq   uint64
+		event event
+		want  bool
+	}{
+		{name: "first event", event: event{Epoch: "a", Seq: 5}, want: false},
+		{name: "next event", epoch: "a", seq: 1, event: event{Epoch: "a", Seq: 2}, want: false},
+		{name: "skipped event", epoch: "a", seq: 1, event: event{Epoch: "a", Seq: 3}, want: true},
+		{name: "repeated event", epoch: "a", seq: 2, event: event{Epoch: "a", Seq: 2}, want: true},
+		{name: "new epoch", epoch: "a", seq: 7, event: event{Epoch: "b", Seq: 1}, want: false},
+		{name: "new epoch skipped", epoch: "a", seq: 7, event: event{Epoch: "b", Seq: 2}, want: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			b := &Broker{epoch: test.epoch, seq: test.seq}
+			if got := b.missed(test.event); got != test.want {
+				t.Errorf("missed() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestHandleMissedEvent(t *testing.T) {
+	b := &Broker{log: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	searcher := &fakeSearcher{}
+	ctx := context.Background()
+
+	b.handle(ctx, searcher, []byte(`{"epoch":"a","seq":1,"added":[1]}`))
+	b.handle(ctx, searcher, []byte(`{"epoch":"a","seq":2,"added":[2]}`))
+	if searcher.builds != 0 || len(searcher.updates) != 2 {
+		t.Fatalf("sequential events: %d builds and %d updates, want 0 and 2", searcher.builds, len(searcher.updates))
+	}
+
+	b.handle(ctx, searcher, []byte(`{"epoch":"a","seq":4,"added":[4]}`))
+	if searcher.builds != 1 || len(searcher.updates) != 2 {
+		t.Fatalf("missed event: %d builds and %d updates, want 1 and 2", searcher.builds, len(searcher.updates))
+	}
+
+	b.handle(ctx, searcher, []byte(`{"epoch":"a","seq":5,"added":[5]}`))
+	if searcher.builds != 1 || len(searcher.updates) != 3 {
+		t.Errorf("event after rebuild: %d builds and %d updates, want 1 and 3", searcher.builds, len(searcher.updates))
+	}
+
+	b.handle(ctx, searcher, []byte(`not json`))
+	if searcher.builds != 2 {
+		t.Errorf("undecodable event: %d builds, want 2", searcher.builds)
+	}
+}
+
+func TestRebuildTimeout(t *testing.T) {
+	b := &Broker{log: slog.New(slog.NewTextHandler(io.Discard, nil)), epoch: "a", seq: 1}
+	searcher := &fakeSearcher{}
+	ctx, cancel := context.WithTimeout(context.Background(), indexUpdateTimeout)
+	cancel()
+
+	b.handle(ctx, searcher, []byte(`{"epoch":"a","seq":3,"added":[3]}`))
+	if searcher.builds != 1 {
+		t.Fatalf("missed event: %d builds, want 1", searcher.builds)
+	}
+	if searcher.canceled {
+		t.Error("index is rebuilt with the context of the event")
+	}
+	if left := time.Until(searcher.deadline); left <= indexUpdateTimeout {
+		t.Errorf("index is rebuilt in %v, want longer than an update", left)
+	}
+}
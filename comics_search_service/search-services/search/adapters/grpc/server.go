@@ -7,6 +7,7 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 	searchpb "yadro.com/course/proto/search"
 	"yadro.com/course/search/core"
 )
@@ -29,47 +30,166 @@ func (s *Server) Ping(_ context.Context, _ *emptypb.Empty) (*emptypb.Empty, erro
 func (s *Server) Search(
 	ctx context.Context, req *searchpb.SearchRequest,
 ) (*searchpb.SearchReply, error) {
-	if req.Limit == 0 {
-		req.Limit = defaultLimit
+	result, err := s.service.Search(ctx, searchRequest(req))
+	if err != nil {
+		return nil, searchError(err)
 	}
-	results, err := s.service.Search(ctx, req.Phrase, int(req.Limit))
+	return searchReply(result), nil
+}
+
+func (s *Server) SearchIndex(
+	ctx context.Context, req *searchpb.SearchRequest,
+) (*searchpb.SearchReply, error) {
+	result, err := s.service.SearchIndex(ctx, searchRequest(req))
+	if err != nil {
+		return nil, searchError(err)
+	}
+	return searchReply(result), nil
+}
+
+func (s *Server) Get(ctx context.Context, req *searchpb.GetRequest) (*searchpb.ComicsInfo, error) {
+	comics, err := s.service.Get(ctx, int(req.Id))
 	if err != nil {
 		if errors.Is(err, core.ErrNotFound) {
-			return nil, status.Error(codes.NotFound, "nothing found")
+			return nil, status.Error(codes.NotFound, "comics not found")
 		}
 		return nil, err
 	}
-	comics := make([]*searchpb.Comics, 0, len(results))
-	for _, c := range results {
-		comics = append(comics, &searchpb.Comics{
-			Id:    int64(c.ID),
-			Url:   c.URL,
-			Score: int64(c.Score),
-		})
+	return comicsInfo(comics), nil
+}
+
+var listOrders = map[searchpb.ListOrder]core.ListOrder{
+	searchpb.ListOrder_LIST_ORDER_ID_ASC:         core.ListByID,
+	searchpb.ListOrder_LIST_ORDER_ID_DESC:        core.ListByIDDesc,
+	searchpb.ListOrder_LIST_ORDER_PUBLISHED_ASC:  core.ListByPublished,
+	searchpb.ListOrder_LIST_ORDER_PUBLISHED_DESC: core.ListByPublishedDesc,
+	searchpb.ListOrder_LIST_ORDER_KEYWORDS_ASC:   core.ListByKeywords,
+	searchpb.ListOrder_LIST_ORDER_KEYWORDS_DESC:  core.ListByKeywordsDesc,
+}
+
+func (s *Server) List(ctx context.Context, req *searchpb.ListRequest) (*searchpb.ListReply, error) {
+	order, ok := listOrders[req.Order]
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "unknown order")
+	}
+	if req.Limit == 0 {
+		req.Limit = defaultLimit
+	}
+	result, err := s.service.List(ctx, core.ListRequest{
+		Limit:  int(req.Limit),
+		Offset: int(req.Offset),
+		Order:  order,
+	})
+	if err != nil {
+		return nil, searchError(err)
+	}
+	reply := &searchpb.ListReply{
+		Comics: make([]*searchpb.ComicsInfo, 0, len(result.Comics)),
+		Total:  int64(result.Total),
 	}
-	return &searchpb.SearchReply{Comics: comics}, nil
+	for _, c := range result.Comics {
+		reply.Comics = append(reply.Comics, comicsInfo(c))
+	}
+	return reply, nil
 }
 
-func (s *Server) SearchIndex(
-	ctx context.Context, req *searchpb.SearchRequest,
+func (s *Server) Related(
+	ctx context.Context, req *searchpb.RelatedRequest,
 ) (*searchpb.SearchReply, error) {
 	if req.Limit == 0 {
 		req.Limit = defaultLimit
 	}
-	results, err := s.service.SearchIndex(ctx, req.Phrase, int(req.Limit))
+	result, err := s.service.Related(ctx, int(req.Id), int(req.Limit))
 	if err != nil {
-		if errors.Is(err, core.ErrNotFound) {
-			return nil, status.Error(codes.NotFound, "nothing found")
-		}
-		return nil, err
+		return nil, searchError(err)
+	}
+	return searchReply(result), nil
+}
+
+func (s *Server) Suggest(
+	ctx context.Context, req *searchpb.SuggestRequest,
+) (*searchpb.SuggestReply, error) {
+	if req.Limit == 0 {
+		req.Limit = defaultLimit
+	}
+	suggestions, err := s.service.Suggest(ctx, req.Prefix, int(req.Limit))
+	if err != nil {
+		return nil, searchError(err)
 	}
-	comics := make([]*searchpb.Comics, 0, len(results))
-	for _, c := range results {
+	reply := &searchpb.SuggestReply{Suggestions: make([]*searchpb.Suggestion, 0, len(suggestions))}
+	for _, suggestion := range suggestions {
+		reply.Suggestions = append(reply.Suggestions, &searchpb.Suggestion{
+			Text:      suggestion.Text,
+			Keyword:   suggestion.Keyword,
+			Frequency: int64(suggestion.Frequency),
+		})
+	}
+	return reply, nil
+}
+
+func comicsInfo(comics core.Comics) *searchpb.ComicsInfo {
+	info := &searchpb.ComicsInfo{
+		Id:         int64(comics.ID),
+		Url:        comics.URL,
+		Title:      comics.Title,
+		SafeTitle:  comics.SafeTitle,
+		Alt:        comics.Alt,
+		Transcript: comics.Transcript,
+		Permalink:  comics.Permalink,
+	}
+	if !comics.Published.IsZero() {
+		info.Published = timestamppb.New(comics.Published)
+	}
+	return info
+}
+
+func searchRequest(req *searchpb.SearchRequest) core.SearchRequest {
+	if req.Limit == 0 {
+		req.Limit = defaultLimit
+	}
+	filter := core.Filter{MinID: int(req.MinId), MaxID: int(req.MaxId)}
+	if req.From != nil {
+		filter.From = req.From.AsTime()
+	}
+	if req.To != nil {
+		filter.To = req.To.AsTime()
+	}
+	return core.SearchRequest{
+		Phrase:      req.Phrase,
+		Limit:       int(req.Limit),
+		Offset:      int(req.Offset),
+		PageToken:   req.PageToken,
+		Fuzzy:       req.Fuzzy,
+		Filter:      filter,
+		AutoCorrect: req.AutoCorrect,
+	}
+}
+
+func searchError(err error) error {
+	if errors.Is(err, core.ErrNotFound) {
+		return status.Error(codes.NotFound, "nothing found")
+	}
+	if errors.Is(err, core.ErrBadArguments) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return err
+}
+
+func searchReply(result core.SearchResult) *searchpb.SearchReply {
+	comics := make([]*searchpb.Comics, 0, len(result.Comics))
+	for _, c := range result.Comics {
 		comics = append(comics, &searchpb.Comics{
-			Id:    int64(c.ID),
-			Url:   c.URL,
-			Score: int64(c.Score),
+			Id:      int64(c.ID),
+			Url:     c.URL,
+			Score:   c.Score,
+			Snippet: c.Snippet,
 		})
 	}
-	return &searchpb.SearchReply{Comics: comics}, nil
+	return &searchpb.SearchReply{
+		Comics:        comics,
+		Total:         int64(result.Total),
+		NextPageToken: result.NextPageToken,
+		Suggestion:    result.Suggestion,
+		Corrected:     result.Corrected,
+	}
 }
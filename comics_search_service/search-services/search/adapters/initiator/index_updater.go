@@ -12,9 +12,12 @@ func RunIndexUpdate(
 	ctx context.Context, searcher core.Searcher, period time.Duration, log *slog.Logger,
 ) {
 	go func() {
-		// update on start
-		if err := searcher.BuildIndex(ctx); err != nil {
-			log.Error("failed to build index on start", "error", err)
+		// restore or update on start
+		if err := searcher.RestoreIndex(ctx); err != nil {
+			log.Info("could not restore index from snapshot, building", "reason", err)
+			if err := searcher.BuildIndex(ctx); err != nil {
+				log.Error("failed to build index on start", "error", err)
+			}
 		}
 		ticker := time.NewTicker(period)
 		for {
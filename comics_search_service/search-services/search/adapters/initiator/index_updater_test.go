@@ -0,0 +1,164 @@
+package initiator
+
+import (
+	"context"
+	"errors"
+	"io"
+	"iter"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"yadro.com/course/search/adapters/snapshot"
+	"yadro.com/course/search/core"
+)
+
+var errNotSupported = errors.New("not supported by fake")
+
+// fakeDB keeps comics in ID order, it only feeds building the index.
+type fakeDB struct {
+	comics []core.Comics
+}
+
+func (fakeDB) Search(context.Context, core.Field, string) ([]core.Posting, error) {
+	return nil, errNotSupported
+}
+
+func (fakeDB) Get(context.Context, int) (core.Comics, error) {
+	return core.Comics{}, errNotSupported
+}
+
+func (fakeDB) List(context.Context, core.ListRequest) ([]core.Comics, error) {
+	return nil, errNotSupported
+}
+
+func (fakeDB) GetMany(context.Context, []int) ([]core.Comics, error) {
+	return nil, errNotSupported
+}
+
+func (db fakeDB) All(context.Context) iter.Seq2[core.Comics, error] {
+	return func(yield func(core.Comics, error) bool) {
+		for _, c := range db.comics {
+			if !yield(c, nil) {
+				return
+			}
+		}
+	}
+}
+
+func (fakeDB) Published(context.Context, []int) (map[int]time.Time, error) {
+	return nil, errNotSupported
+}
+
+func (db fakeDB) LastID(context.Context) (int, error) {
+	if len(db.comics) == 0 {
+		return 0, nil
+	}
+	return db.comics[len(db.comics)-1].ID, nil
+}
+
+func (fakeDB) Updated(context.Context) (time.Time, error) {
+	return time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), nil
+}
+
+func (db fakeDB) Stats(context.Context) (map[core.Field]core.CorpusStats, error) {
+	return map[core.Field]core.CorpusStats{core.FieldWords: {Documents: len(db.comics)}}, nil
+}
+
+func (fakeDB) Expand(context.Context, core.Field, string, int) ([]string, error) {
+	return nil, errNotSupported
+}
+
+func (fakeDB) Keywords(context.Context, core.Field, int) ([]string, error) {
+	return nil, errNotSupported
+}
+
+// fakeWords stands for the words service being down.
+type fakeWords struct{}
+
+func (fakeWords) Norm(context.Context, string) ([]string, error) {
+	return nil, errNotSupported
+}
+
+func (fakeWords) Expand(context.Context, string) ([][]string, error) {
+	return nil, errNotSupported
+}
+
+func (fakeWords) Analyze(context.Context, string) ([]core.Token, error) {
+	return nil, errNotSupported
+}
+
+func (fakeWords) AnalyzeBatch(context.Context, []string) ([][]core.Token, error) {
+	return nil, errNotSupported
+}
+
+func newTestService(t *testing.T, db core.DB, snapshots core.Snapshots) *core.Service {
+	t.Helper()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	service, err := core.NewService(
+		log, db, fakeWords{}, core.BM25{K1: 1.2, B: 0.75}, 10, core.Highlighter{Words: 10}, snapshots,
+	)
+	if err != nil {
+		t.Fatalf("failed to create service: %v", err)
+	}
+	return service
+}
+
+func TestRunIndexUpdateCorruptedSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	snapshots, err := snapshot.New(log, dir)
+	if err != nil {
+		t.Fatalf("failed to create snapshots: %v", err)
+	}
+	db := fakeDB{comics: []core.Comics{
+		{ID: 1, Keywords: map[core.Field][]string{core.FieldWords: {"linux"}}},
+		{ID: 2, Keywords: map[core.Field][]string{core.FieldWords: {"kernel"}}},
+	}}
+	if err := newTestService(t, db, snapshots).BuildIndex(context.Background()); err != nil {
+		t.Fatalf("failed to build index: %v", err)
+	}
+
+	// the snapshot is up to date with DB but its payload is damaged
+	path := filepath.Join(dir, "index.snapshot")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read snapshot: %v", err)
+	}
+	data[len(data)-1] ^= 0xff
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("failed to write snapshot: %v", err)
+	}
+	corrupted, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("failed to stat snapshot: %v", err)
+	}
+
+	service := newTestService(t, db, snapshots)
+	if err := service.RestoreIndex(context.Background()); err == nil {
+		t.Fatal("corrupted snapshot is restored")
+	}
+	RunIndexUpdate(context.Background(), service, time.Hour, log)
+
+	// the index is rebuilt from DB and saved again
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		info, err := os.Stat(path)
+		if err == nil && !os.SameFile(info, corrupted) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("index is not rebuilt from DB")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	restored, err := snapshots.Load()
+	if err != nil {
+		t.Fatalf("rebuilt snapshot is not loaded: %v", err)
+	}
+	if restored.Documents() != 2 || restored.LastID != 2 {
+		t.Errorf("rebuilt %d comics up to %d, want 2 up to 2", restored.Documents(), restored.LastID)
+	}
+}
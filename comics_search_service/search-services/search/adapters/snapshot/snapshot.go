@@ -0,0 +1,125 @@
+package snapshot
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"yadro.com/course/closers"
+	"yadro.com/course/search/core"
+)
+
+const fileName = "index.snapshot"
+
+// file layout: header followed by gob encoded core.IndexSnapshot
+const (
+	magic         = "XKCDIDX"
+	formatVersion = 1
+)
+
+type header struct {
+	Magic    [len(magic)]byte
+	Version  uint32
+	Checksum uint32 // CRC-32C of the payload
+	Size     uint64
+}
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+type Storage struct {
+	log  *slog.Logger
+	path string
+}
+
+func New(log *slog.Logger, dir string) (*Storage, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create snapshot dir: %v", err)
+	}
+	return &Storage{log: log, path: filepath.Join(dir, fileName)}, nil
+}
+
+// Save writes snapshot to a temporary file and renames it,
+// so that a crash while saving does not destroy the previous snapshot.
+func (s *Storage) Save(snapshot core.IndexSnapshot) error {
+	var payload bytes.Buffer
+	if err := gob.NewEncoder(&payload).Encode(snapshot); err != nil {
+		return fmt.Errorf("failed to encode snapshot: %v", err)
+	}
+	h := header{
+		Version:  formatVersion,
+		Checksum: crc32.Checksum(payload.Bytes(), crcTable),
+		Size:     uint64(payload.Len()),
+	}
+	copy(h.Magic[:], magic)
+
+	file, err := os.CreateTemp(filepath.Dir(s.path), fileName+".*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := os.Remove(file.Name()); err != nil && !errors.Is(err, os.ErrNotExist) {
+			s.log.Warn("failed to remove temporary snapshot", "error", err)
+		}
+	}()
+	if err := binary.Write(file, binary.LittleEndian, h); err != nil {
+		closers.CloseOrLog(file, s.log)
+		return err
+	}
+	if _, err := payload.WriteTo(file); err != nil {
+		closers.CloseOrLog(file, s.log)
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		closers.CloseOrLog(file, s.log)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(file.Name(), s.path); err != nil {
+		return err
+	}
+	s.log.Debug("saved index snapshot", "path", s.path, "size", h.Size)
+	return nil
+}
+
+func (s *Storage) Load() (core.IndexSnapshot, error) {
+	file, err := os.Open(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return core.IndexSnapshot{}, fmt.Errorf("%w: no snapshot at %s", core.ErrNotFound, s.path)
+		}
+		return core.IndexSnapshot{}, err
+	}
+	defer closers.CloseOrLog(file, s.log)
+
+	var h header
+	if err := binary.Read(file, binary.LittleEndian, &h); err != nil {
+		return core.IndexSnapshot{}, fmt.Errorf("corrupted snapshot header: %v", err)
+	}
+	if string(h.Magic[:]) != magic {
+		return core.IndexSnapshot{}, errors.New("not a snapshot file")
+	}
+	if h.Version != formatVersion {
+		return core.IndexSnapshot{}, fmt.Errorf("snapshot format %d is not supported", h.Version)
+	}
+	payload, err := io.ReadAll(file)
+	if err != nil {
+		return core.IndexSnapshot{}, err
+	}
+	if uint64(len(payload)) != h.Size || crc32.Checksum(payload, crcTable) != h.Checksum {
+		return core.IndexSnapshot{}, errors.New("corrupted snapshot: checksum mismatch")
+	}
+	var snapshot core.IndexSnapshot
+	if err := gob.NewDecoder(bytes.NewReader(payload)).Decode(&snapshot); err != nil {
+		return core.IndexSnapshot{}, fmt.Errorf("corrupted snapshot: %v", err)
+	}
+	return snapshot, nil
+}
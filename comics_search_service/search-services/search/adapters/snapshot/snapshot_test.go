@@ -0,0 +1,66 @@
+package snapshot
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+
+	"yadro.com/course/search/core"
+)
+
+func newTestStorage(t *testing.T) *Storage {
+	t.Helper()
+	storage, err := New(slog.New(slog.NewTextHandler(io.Discard, nil)), t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	return storage
+}
+
+func testSnapshot() core.IndexSnapshot {
+	index := core.NewIndex()
+	index.Put(core.Comics{ID: 1, Keywords: map[core.Field][]string{core.FieldWords: {"linux", "kernel"}}})
+	return index.Snapshot(core.DBState{LastID: 1})
+}
+
+func TestSaveLoad(t *testing.T) {
+	storage := newTestStorage(t)
+	if err := storage.Save(testSnapshot()); err != nil {
+		t.Fatalf("failed to save: %v", err)
+	}
+	snapshot, err := storage.Load()
+	if err != nil {
+		t.Fatalf("failed to load: %v", err)
+	}
+	if snapshot.LastID != 1 || snapshot.Documents() != 1 {
+		t.Errorf("loaded %d comics up to %d, want 1 up to 1", snapshot.Documents(), snapshot.LastID)
+	}
+}
+
+// corrupt flips the last byte of the snapshot payload.
+func corrupt(t *testing.T, path string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read snapshot: %v", err)
+	}
+	data[len(data)-1] ^= 0xff
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("failed to write snapshot: %v", err)
+	}
+}
+
+func TestLoadChecksumMismatch(t *testing.T) {
+	storage := newTestStorage(t)
+	if err := storage.Save(testSnapshot()); err != nil {
+		t.Fatalf("failed to save: %v", err)
+	}
+	corrupt(t, storage.path)
+
+	_, err := storage.Load()
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("Load() error = %v, want checksum mismatch", err)
+	}
+}
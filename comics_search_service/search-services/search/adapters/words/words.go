@@ -2,6 +2,7 @@ package words
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 
 	"google.golang.org/grpc"
@@ -45,6 +46,98 @@ func (c *Client) Norm(ctx context.Context, phrase string) ([]string, error) {
 	return reply.GetWords(), nil
 }
 
+func (c *Client) Expand(ctx context.Context, phrase string) ([][]string, error) {
+	reply, err := c.client.Expand(ctx, &wordspb.WordsRequest{Phrase: phrase})
+	if err != nil {
+		if status.Code(err) == codes.ResourceExhausted {
+			return nil, core.ErrBadArguments
+		}
+		return nil, err
+	}
+	synonyms := make([][]string, 0, len(reply.GetSynonyms()))
+	for _, synonym := range reply.GetSynonyms() {
+		synonyms = append(synonyms, synonym.GetWords())
+	}
+	return synonyms, nil
+}
+
+func (c *Client) Analyze(ctx context.Context, phrase string) ([]core.Token, error) {
+	reply, err := c.client.Analyze(ctx, &wordspb.WordsRequest{Phrase: phrase})
+	if err != nil {
+		if status.Code(err) == codes.ResourceExhausted {
+			return nil, core.ErrBadArguments
+		}
+		return nil, err
+	}
+	return appendTokens(nil, reply, 0, 0), nil
+}
+
+// appendTokens converts tokens of a part of a phrase starting at offset
+// after position words, so that they are located in the whole phrase.
+func appendTokens(tokens []core.Token, reply *wordspb.AnalyzeReply, offset, position int) []core.Token {
+	for _, t := range reply.GetTokens() {
+		tokens = append(tokens, core.Token{
+			Text:     t.GetText(),
+			Stem:     t.GetStem(),
+			Start:    offset + int(t.GetStart()),
+			End:      offset + int(t.GetEnd()),
+			Position: position + int(t.GetPosition()),
+			Stop:     t.GetStop(),
+		})
+	}
+	return tokens
+}
+
+// AnalyzeBatch analyzes phrases in a few round-trips. Phrases longer than
+// the words service accepts are split between words, and tokens of the parts are joined.
+func (c *Client) AnalyzeBatch(ctx context.Context, phrases []string) ([][]core.Token, error) {
+	type part struct {
+		owner  int // index of the phrase
+		offset int // in the phrase
+	}
+	var requests []*wordspb.WordsRequest
+	var parts []part
+	for i, phrase := range phrases {
+		var offset int
+		for _, text := range wordspb.SplitPhrase(phrase, wordspb.MaxPhraseLen) {
+			requests = append(requests, &wordspb.WordsRequest{Phrase: text})
+			parts = append(parts, part{owner: i, offset: offset})
+			offset += len(text)
+		}
+	}
+
+	var replies []*wordspb.AnalyzeReply
+	for len(requests) > len(replies) {
+		batch := requests[len(replies):]
+		var total int
+		for j, req := range batch {
+			total += len(req.GetPhrase())
+			if total > wordspb.MaxBatchLen && j > 0 {
+				batch = batch[:j]
+				break
+			}
+		}
+		reply, err := c.client.AnalyzeBatch(ctx, &wordspb.WordsBatchRequest{Requests: batch})
+		if err != nil {
+			if status.Code(err) == codes.ResourceExhausted {
+				return nil, core.ErrBadArguments
+			}
+			return nil, err
+		}
+		if len(reply.GetReplies()) != len(batch) {
+			return nil, fmt.Errorf("got %d replies for %d phrases", len(reply.GetReplies()), len(batch))
+		}
+		replies = append(replies, reply.GetReplies()...)
+	}
+
+	result := make([][]core.Token, len(phrases))
+	for j, reply := range replies {
+		i := parts[j].owner
+		result[i] = appendTokens(result[i], reply, parts[j].offset, len(result[i]))
+	}
+	return result, nil
+}
+
 func (c *Client) Ping(ctx context.Context) error {
 	_, err := c.client.Ping(ctx, nil)
 	return err
@@ -0,0 +1,108 @@
+package words
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"unicode"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+	wordspb "yadro.com/course/proto/words"
+)
+
+var errNotSupported = errors.New("not supported by fake")
+
+// fakeWords analyzes words of each phrase as their own keywords.
+type fakeWords struct {
+	t     *testing.T
+	calls int
+}
+
+func (w *fakeWords) AnalyzeBatch(
+	_ context.Context, in *wordspb.WordsBatchRequest, _ ...grpc.CallOption,
+) (*wordspb.AnalyzeBatchReply, error) {
+	w.calls++
+	reply := &wordspb.AnalyzeBatchReply{}
+	for _, req := range in.GetRequests() {
+		phrase := req.GetPhrase()
+		if len(phrase) > wordspb.MaxPhraseLen {
+			w.t.Errorf("phrase of %d bytes is sent", len(phrase))
+		}
+		analyzed := &wordspb.AnalyzeReply{}
+		start := -1
+		for i, r := range phrase + " " {
+			switch {
+			case unicode.IsLetter(r) && start < 0:
+				start = i
+			case !unicode.IsLetter(r) && start >= 0:
+				analyzed.Tokens = append(analyzed.Tokens, &wordspb.Token{
+					Text: phrase[start:i], Stem: phrase[start:i],
+					Start: int64(start), End: int64(i), Position: int64(len(analyzed.Tokens)),
+				})
+				start = -1
+			}
+		}
+		reply.Replies = append(reply.Replies, analyzed)
+	}
+	return reply, nil
+}
+
+func (*fakeWords) Ping(context.Context, *emptypb.Empty, ...grpc.CallOption) (*emptypb.Empty, error) {
+	return nil, errNotSupported
+}
+
+func (*fakeWords) Norm(context.Context, *wordspb.WordsRequest, ...grpc.CallOption) (*wordspb.WordsReply, error) {
+	return nil, errNotSupported
+}
+
+func (*fakeWords) NormBatch(context.Context, *wordspb.WordsBatchRequest, ...grpc.CallOption) (*wordspb.WordsBatchReply, error) {
+	return nil, errNotSupported
+}
+
+func (*fakeWords) NormStream(
+	context.Context, ...grpc.CallOption,
+) (grpc.BidiStreamingClient[wordspb.WordsRequest, wordspb.WordsReply], error) {
+	return nil, errNotSupported
+}
+
+func (*fakeWords) Version(context.Context, *emptypb.Empty, ...grpc.CallOption) (*wordspb.VersionReply, error) {
+	return nil, errNotSupported
+}
+
+func (*fakeWords) Expand(context.Context, *wordspb.WordsRequest, ...grpc.CallOption) (*wordspb.ExpandReply, error) {
+	return nil, errNotSupported
+}
+
+func (*fakeWords) Analyze(context.Context, *wordspb.WordsRequest, ...grpc.CallOption) (*wordspb.AnalyzeReply, error) {
+	return nil, errNotSupported
+}
+
+func TestAnalyzeBatchLongPhrase(t *testing.T) {
+	words := &fakeWords{t: t}
+	c := &Client{log: slog.New(slog.NewTextHandler(io.Discard, nil)), client: words}
+
+	long := strings.Repeat("linux ", wordspb.MaxPhraseLen/3) + "kernel"
+	analyzed, err := c.AnalyzeBatch(context.Background(), []string{"cat", long})
+	if err != nil {
+		t.Fatalf("AnalyzeBatch failed: %v", err)
+	}
+	if words.calls != 1 {
+		t.Errorf("phrases are analyzed in %d calls, want 1", words.calls)
+	}
+	if len(analyzed) != 2 || len(analyzed[0]) != 1 {
+		t.Fatalf("got tokens %v", analyzed)
+	}
+	tokens := analyzed[1]
+	if len(tokens) != wordspb.MaxPhraseLen/3+1 {
+		t.Fatalf("long phrase has %d tokens, want %d", len(tokens), wordspb.MaxPhraseLen/3+1)
+	}
+	for i, token := range tokens {
+		if token.Position != i || long[token.Start:token.End] != token.Text {
+			t.Fatalf("token %d %+v is not located in the phrase", i, token)
+		}
+	}
+}
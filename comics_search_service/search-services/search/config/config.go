@@ -7,13 +7,35 @@ import (
 	"github.com/ilyakaznacheev/cleanenv"
 )
 
+type BM25 struct {
+	K1 float64 `yaml:"k1" env:"BM25_K1" env-default:"1.2"`
+	B  float64 `yaml:"b" env:"BM25_B" env-default:"0.75"`
+}
+
+type Boosts struct {
+	Title      float64 `yaml:"title" env:"BOOST_TITLE" env-default:"2"`
+	Alt        float64 `yaml:"alt" env:"BOOST_ALT" env-default:"1"`
+	Transcript float64 `yaml:"transcript" env:"BOOST_TRANSCRIPT" env-default:"0.5"`
+}
+
+type Snippet struct {
+	Words   int    `yaml:"words" env:"SNIPPET_WORDS" env-default:"30"`
+	PreTag  string `yaml:"pre_tag" env:"SNIPPET_PRE_TAG" env-default:"<em>"`
+	PostTag string `yaml:"post_tag" env:"SNIPPET_POST_TAG" env-default:"</em>"`
+}
+
 type Config struct {
 	LogLevel      string        `yaml:"log_level" env:"LOG_LEVEL" env-default:"DEBUG"`
 	IndexTTL      time.Duration `yaml:"index_ttl" env:"INDEX_TTL" env-default:"1h"`
+	SnapshotDir   string        `yaml:"snapshot_dir" env:"SNAPSHOT_DIR"`
 	Address       string        `yaml:"search_address" env:"SEARCH_ADDRESS" env-default:"localhost:80"`
 	DBAddress     string        `yaml:"db_address" env:"DB_ADDRESS" env-default:"localhost:82"`
 	WordsAddress  string        `yaml:"words_address" env:"WORDS_ADDRESS" env-default:"localhost:81"`
 	BrokerAddress string        `yaml:"broker_address" env:"BROKER_ADDRESS" env-default:"localhost:4222"`
+	BM25          BM25          `yaml:"bm25"`
+	Boosts        Boosts        `yaml:"boosts"`
+	MaxExpansions int           `yaml:"max_expansions" env:"MAX_EXPANSIONS" env-default:"50"`
+	Snippet       Snippet       `yaml:"snippet"`
 }
 
 func MustLoad(configPath string) Config {
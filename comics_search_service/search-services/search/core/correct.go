@@ -0,0 +1,91 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+)
+
+// Correct returns the index keyword closest to a keyword unknown to the index,
+// the most frequent of equally close ones.
+func (i *Index) Correct(keyword string) (string, bool) {
+	maxDistance := fuzziness(keyword)
+	if maxDistance == 0 {
+		return "", false
+	}
+	length := utf8.RuneCountInString(keyword)
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	f := i.fields[FieldWords]
+	if _, ok := f.index[keyword]; ok {
+		return "", false
+	}
+	var best string
+	bestDistance := maxDistance + 1
+	for _, term := range f.terms {
+		if n := utf8.RuneCountInString(term); n > length+maxDistance || n < length-maxDistance-1 {
+			continue
+		}
+		d := fuzzyDistance(keyword, term)
+		switch {
+		case d > maxDistance:
+		case d < bestDistance,
+			d == bestDistance && len(f.index[term]) > len(f.index[best]),
+			d == bestDistance && len(f.index[term]) == len(f.index[best]) && term < best:
+			best, bestDistance = term, d
+		}
+	}
+	return best, best != ""
+}
+
+// Form returns the most frequent word of comics text normalized to keyword.
+func (i *Index) Form(keyword string) string {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	if form, ok := i.forms[keyword]; ok {
+		return form
+	}
+	return keyword
+}
+
+// correct returns the phrase with words unknown to the index replaced
+// by the closest index keywords written as they usually are in comics.
+// Operators, field names and wildcards are kept as is.
+func (s *Service) correct(ctx context.Context, phrase string) (string, error) {
+	var b strings.Builder
+	var last int
+	for _, w := range split(phrase) {
+		word := phrase[w.start:w.end]
+		if !correctable(phrase, w) {
+			continue
+		}
+		keywords, err := s.words.Norm(ctx, word)
+		if err != nil {
+			return "", err
+		}
+		for _, keyword := range keywords {
+			if closest, ok := s.index.Correct(keyword); ok {
+				b.WriteString(phrase[last:w.start])
+				b.WriteString(s.index.Form(closest))
+				last = w.end
+				break
+			}
+		}
+	}
+	if last == 0 {
+		return phrase, nil
+	}
+	b.WriteString(phrase[last:])
+	return b.String(), nil
+}
+
+func correctable(phrase string, w span) bool {
+	switch phrase[w.start:w.end] {
+	case "AND", "OR", "NOT":
+		return false
+	}
+	if w.end < len(phrase) && strings.ContainsRune(":*?", rune(phrase[w.end])) {
+		return false
+	}
+	return w.start == 0 || !strings.ContainsRune("*?", rune(phrase[w.start-1]))
+}
@@ -0,0 +1,53 @@
+package core
+
+import (
+	"cmp"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+)
+
+// cursor points to the last returned comics, the next page starts right after it.
+// It is bound to the query so that a token can not be replayed with another one.
+type cursor struct {
+	Query string  `json:"q"`
+	Score float64 `json:"s"`
+	ID    int     `json:"i"`
+}
+
+// fingerprint identifies the query a page token was issued for.
+func (r SearchRequest) fingerprint() string {
+	h := fnv.New64a()
+	_, _ = fmt.Fprintf(h, "%s\x00%t\x00%v", r.Phrase, r.Fuzzy, r.Filter)
+	return strconv.FormatUint(h.Sum64(), 36)
+}
+
+func encodeCursor(c cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(token string, req SearchRequest) (cursor, error) {
+	var c cursor
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return cursor{}, fmt.Errorf("%w: malformed page token", ErrBadArguments)
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return cursor{}, fmt.Errorf("%w: malformed page token", ErrBadArguments)
+	}
+	if c.Query != req.fingerprint() {
+		return cursor{}, fmt.Errorf("%w: page token belongs to another query", ErrBadArguments)
+	}
+	return c, nil
+}
+
+// compareResults orders results by relevance, then by ID for equal relevance.
+func compareResults(scoreA float64, idA int, scoreB float64, idB int) int {
+	if c := cmp.Compare(scoreB, scoreA); c != 0 {
+		return c // desc
+	}
+	return cmp.Compare(idA, idB)
+}
@@ -0,0 +1,368 @@
+package core
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"time"
+	"unicode/utf8"
+)
+
+// comics ID -> relevance
+type scores map[int]float64
+
+// source is where query keywords are looked up: the index or the DB.
+type source interface {
+	postings(ctx context.Context, field Field, keyword string) ([]Posting, error)
+	expand(ctx context.Context, field Field, pattern string, limit int) ([]string, error)
+	keywords(ctx context.Context, field Field, maxLength int) ([]string, error)
+	published(ctx context.Context, IDs []int) (map[int]time.Time, error)
+}
+
+type indexSource struct {
+	index *Index
+}
+
+func (s indexSource) postings(_ context.Context, field Field, keyword string) ([]Posting, error) {
+	return s.index.Get(field, keyword), nil
+}
+
+func (s indexSource) expand(_ context.Context, field Field, pattern string, limit int) ([]string, error) {
+	return s.index.Expand(field, pattern, limit), nil
+}
+
+func (s indexSource) keywords(_ context.Context, field Field, maxLength int) ([]string, error) {
+	return s.index.Keywords(field, maxLength), nil
+}
+
+func (s indexSource) published(_ context.Context, IDs []int) (map[int]time.Time, error) {
+	return s.index.Published(IDs), nil
+}
+
+type dbSource struct {
+	db DB
+}
+
+func (s dbSource) postings(ctx context.Context, field Field, keyword string) ([]Posting, error) {
+	return s.db.Search(ctx, field, keyword)
+}
+
+func (s dbSource) expand(ctx context.Context, field Field, pattern string, limit int) ([]string, error) {
+	return s.db.Expand(ctx, field, pattern, limit)
+}
+
+func (s dbSource) keywords(ctx context.Context, field Field, maxLength int) ([]string, error) {
+	return s.db.Keywords(ctx, field, maxLength)
+}
+
+func (s dbSource) published(ctx context.Context, IDs []int) (map[int]time.Time, error) {
+	return s.db.Published(ctx, IDs)
+}
+
+type evaluator struct {
+	ranker        BM25
+	stats         map[Field]CorpusStats
+	source        source
+	maxExpansions int
+	fuzzy         bool
+	keywords      map[fieldKeyword]scores
+	postings      map[fieldKeyword][]Posting
+	negated       bool            // evaluating excluded clauses
+	matched       map[string]bool // keywords found by positive clauses
+}
+
+func newEvaluator(
+	ranker BM25, stats map[Field]CorpusStats, source source, maxExpansions int, fuzzy bool,
+) *evaluator {
+	return &evaluator{
+		ranker:        ranker,
+		stats:         stats,
+		source:        source,
+		maxExpansions: maxExpansions,
+		fuzzy:         fuzzy,
+		keywords:      make(map[fieldKeyword]scores),
+		postings:      make(map[fieldKeyword][]Posting),
+		matched:       make(map[string]bool),
+	}
+}
+
+func (e *evaluator) eval(ctx context.Context, n node) (scores, error) {
+	switch n := n.(type) {
+	case *termNode:
+		if n.wildcard {
+			return e.evalWildcard(ctx, n)
+		}
+		return e.evalTerm(ctx, n)
+	case *boolNode:
+		return e.evalBool(ctx, n)
+	}
+	return scores{}, nil
+}
+
+// evalTerm scores comics by the best matching synonym of a term or a phrase.
+func (e *evaluator) evalTerm(ctx context.Context, n *termNode) (scores, error) {
+	result, err := e.evalKeywords(ctx, n, n.stems)
+	if err != nil {
+		return nil, err
+	}
+	for _, synonym := range n.synonyms {
+		found, err := e.evalKeywords(ctx, n, synonym)
+		if err != nil {
+			return nil, err
+		}
+		for ID, score := range found {
+			result[ID] = max(result[ID], score)
+		}
+	}
+	return result, nil
+}
+
+// evalKeywords requires all keywords of a term or a phrase to be present,
+// keywords of a phrase must also follow each other in the same order.
+// Terms, but not phrases, tolerate typos in fuzzy mode.
+func (e *evaluator) evalKeywords(ctx context.Context, n *termNode, keywords []string) (scores, error) {
+	var result scores
+	seen := make(map[string]bool, len(keywords))
+	for _, stem := range keywords {
+		if seen[stem] {
+			continue // a repeated keyword of a phrase
+		}
+		seen[stem] = true
+		lookup := e.keyword
+		if e.fuzzy && !n.phrase {
+			lookup = e.fuzzyKeyword
+		}
+		found, err := lookup(ctx, n.field, stem)
+		if err != nil {
+			return nil, err
+		}
+		if result == nil {
+			result = maps.Clone(found)
+			continue
+		}
+		result = intersect(result, found)
+	}
+	if n.phrase && len(keywords) > 1 && len(result) > 0 {
+		return e.adjacent(ctx, n.field.orWords(), keywords, result)
+	}
+	return result, nil
+}
+
+// adjacent keeps found comics having keywords in a row in the field.
+func (e *evaluator) adjacent(ctx context.Context, field Field, keywords []string, found scores) (scores, error) {
+	positions := make([]map[int][]int, len(keywords)) // comics ID -> positions of each keyword
+	for i, keyword := range keywords {
+		postings, err := e.lookup(ctx, field, keyword)
+		if err != nil {
+			return nil, err
+		}
+		positions[i] = make(map[int][]int, len(postings))
+		for _, p := range postings {
+			positions[i][p.ID] = p.Positions
+		}
+	}
+	result := make(scores)
+	for ID, score := range found {
+		if inRow(positions, ID) {
+			result[ID] = score
+		}
+	}
+	return result, nil
+}
+
+func inRow(positions []map[int][]int, ID int) bool {
+	for _, start := range positions[0][ID] {
+		next := 1
+		for next < len(positions) {
+			if _, ok := slices.BinarySearch(positions[next][ID], start+next); !ok {
+				break
+			}
+			next++
+		}
+		if next == len(positions) {
+			return true
+		}
+	}
+	return false
+}
+
+// evalWildcard scores comics by the best matching expansion of a pattern.
+func (e *evaluator) evalWildcard(ctx context.Context, n *termNode) (scores, error) {
+	result := scores{}
+	for _, pattern := range n.stems {
+		keywords, err := e.source.expand(ctx, n.field.orWords(), pattern, e.maxExpansions)
+		if err != nil {
+			return nil, err
+		}
+		for _, keyword := range keywords {
+			found, err := e.keyword(ctx, n.field, keyword)
+			if err != nil {
+				return nil, err
+			}
+			for ID, score := range found {
+				result[ID] = max(result[ID], score)
+			}
+		}
+	}
+	return result, nil
+}
+
+func (e *evaluator) evalBool(ctx context.Context, n *boolNode) (scores, error) {
+	var result scores
+	for _, c := range n.clauses {
+		found, err := e.eval(ctx, c)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case result == nil:
+			result = found
+		case n.op == opAnd:
+			result = intersect(result, found)
+		default:
+			for ID, score := range found {
+				result[ID] += score
+			}
+		}
+	}
+	e.negated = !e.negated
+	defer func() { e.negated = !e.negated }()
+	for _, c := range n.excludes {
+		found, err := e.eval(ctx, c)
+		if err != nil {
+			return nil, err
+		}
+		for ID := range found {
+			delete(result, ID)
+		}
+	}
+	return result, nil
+}
+
+// fieldKeyword is a keyword searched in a field or in any field if the field is empty.
+type fieldKeyword struct {
+	field   Field
+	keyword string
+}
+
+// keyword scores comics by a keyword found in the field. Comics are scored
+// by all their keywords when no field is specified, and keywords found in
+// boosted fields add up to the score.
+func (e *evaluator) keyword(ctx context.Context, field Field, keyword string) (scores, error) {
+	key := fieldKeyword{field: field, keyword: keyword}
+	found, ok := e.keywords[key]
+	if !ok {
+		var err error
+		if field != "" {
+			found, err = e.scoreField(ctx, field, keyword, e.ranker.Boosts[field])
+		} else {
+			found, err = e.scoreAnyField(ctx, keyword)
+		}
+		if err != nil {
+			return nil, err
+		}
+		e.keywords[key] = found
+	}
+	if !e.negated && len(found) > 0 {
+		e.matched[keyword] = true
+	}
+	return found, nil
+}
+
+func (e *evaluator) scoreAnyField(ctx context.Context, keyword string) (scores, error) {
+	found, err := e.scoreField(ctx, FieldWords, keyword, 1)
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range Fields {
+		boost := e.ranker.Boosts[field]
+		if boost == 0 {
+			continue
+		}
+		inField, err := e.scoreField(ctx, field, keyword, boost)
+		if err != nil {
+			return nil, err
+		}
+		for ID, score := range inField {
+			if _, ok := found[ID]; ok {
+				found[ID] += score
+			}
+		}
+	}
+	return found, nil
+}
+
+func (e *evaluator) scoreField(
+	ctx context.Context, field Field, keyword string, boost float64,
+) (scores, error) {
+	postings, err := e.lookup(ctx, field, keyword)
+	if err != nil {
+		return nil, err
+	}
+	stats := e.stats[field]
+	idf := e.ranker.IDF(len(postings), stats)
+	found := make(scores, len(postings))
+	for _, p := range postings {
+		found[p.ID] += boost * e.ranker.Score(p, idf, stats)
+	}
+	return found, nil
+}
+
+// lookup returns postings of a keyword in the field, they are fetched once per query.
+func (e *evaluator) lookup(ctx context.Context, field Field, keyword string) ([]Posting, error) {
+	key := fieldKeyword{field: field, keyword: keyword}
+	if postings, ok := e.postings[key]; ok {
+		return postings, nil
+	}
+	postings, err := e.source.postings(ctx, field, keyword)
+	if err != nil {
+		return nil, err
+	}
+	e.postings[key] = postings
+	return postings, nil
+}
+
+// fuzzyKeyword scores comics by the keyword and keywords similar to it,
+// the latter are weighted down by edit distance.
+func (e *evaluator) fuzzyKeyword(ctx context.Context, field Field, keyword string) (scores, error) {
+	exact, err := e.keyword(ctx, field, keyword)
+	if err != nil {
+		return nil, err
+	}
+	maxDistance := fuzziness(keyword)
+	if maxDistance == 0 {
+		return exact, nil
+	}
+	candidates, err := e.source.keywords(ctx, field.orWords(), utf8.RuneCountInString(keyword)+maxDistance)
+	if err != nil {
+		return nil, err
+	}
+	result := maps.Clone(exact)
+	for _, candidate := range candidates {
+		if candidate == keyword {
+			continue
+		}
+		d := fuzzyDistance(keyword, candidate)
+		if d > maxDistance {
+			continue
+		}
+		found, err := e.keyword(ctx, field, candidate)
+		if err != nil {
+			return nil, err
+		}
+		for ID, score := range found {
+			result[ID] = max(result[ID], fuzzyWeight(d)*score)
+		}
+	}
+	return result, nil
+}
+
+func intersect(a, b scores) scores {
+	result := make(scores)
+	for ID, score := range a {
+		if other, ok := b[ID]; ok {
+			result[ID] = score + other
+		}
+	}
+	return result
+}
@@ -0,0 +1,70 @@
+package core
+
+import (
+	"context"
+	"testing"
+)
+
+func evalIndex(t *testing.T, index *Index, n node) scores {
+	t.Helper()
+	e := newEvaluator(BM25{K1: 1.2, B: 0.75}, index.Stats(), indexSource{index: index}, 10, false)
+	found, err := e.eval(context.Background(), n)
+	if err != nil {
+		t.Fatalf("eval failed: %v", err)
+	}
+	return found
+}
+
+func TestEvalTermFrequency(t *testing.T) {
+	index := newTestIndex(
+		testComics(1, "linux", "kernel", "window"),
+		testComics(2, "linux", "linux", "kernel"),
+		testComics(3, "window", "kernel", "mac"),
+	)
+	found := evalIndex(t, index, &termNode{text: "linux", stems: []string{"linux"}})
+	if len(found) != 2 {
+		t.Fatalf("found %v, want comics 1 and 2", found)
+	}
+	if found[2] <= found[1] {
+		t.Errorf("comics repeating the keyword scored %v, not above %v of the single mention", found[2], found[1])
+	}
+}
+
+func TestEvalPhraseOrder(t *testing.T) {
+	index := newTestIndex(
+		testComics(1, "sudo", "make", "sandwich"),
+		testComics(2, "sandwich", "sudo", "make"),
+		testComics(3, "make", "sandwich", "sudo", "make", "sandwich"),
+	)
+	tests := []struct {
+		name   string
+		phrase []string
+		want   []int
+	}{
+		{name: "in order", phrase: []string{"sudo", "make", "sandwich"}, want: []int{1, 3}},
+		{name: "out of order", phrase: []string{"sandwich", "sudo"}, want: []int{2, 3}},
+		{name: "not in a row", phrase: []string{"sandwich", "make", "sudo"}, want: nil},
+		{name: "repeated keyword", phrase: []string{"sandwich", "sandwich"}, want: nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			found := evalIndex(t, index, &termNode{phrase: true, stems: test.phrase})
+			if len(found) != len(test.want) {
+				t.Fatalf("found %v, want comics %v", found, test.want)
+			}
+			for _, ID := range test.want {
+				if _, ok := found[ID]; !ok {
+					t.Errorf("found %v, want comics %v", found, test.want)
+				}
+			}
+		})
+	}
+
+	found := evalIndex(t, index, &boolNode{op: opAnd, clauses: []node{
+		&termNode{stems: []string{"sandwich"}},
+		&termNode{stems: []string{"sudo"}},
+	}})
+	if len(found) != 3 {
+		t.Errorf("keywords without a phrase found %v, want all comics", found)
+	}
+}
@@ -0,0 +1,270 @@
+package core
+
+import (
+	"cmp"
+	"context"
+	"io"
+	"iter"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func newTestIndex(comics ...Comics) *Index {
+	index := NewIndex()
+	for _, c := range comics {
+		index.Put(c)
+	}
+	return index
+}
+
+// testComics returns comics with keywords of the whole comics only.
+func testComics(ID int, keywords ...string) Comics {
+	return Comics{ID: ID, Keywords: map[Field][]string{FieldWords: keywords}}
+}
+
+// titledComics returns comics with the title and its lowercased words as keywords.
+func titledComics(ID int, title string) Comics {
+	var keywords []string
+	for _, s := range split(title) {
+		keywords = append(keywords, strings.ToLower(title[s.start:s.end]))
+	}
+	return Comics{ID: ID, Title: title, Keywords: map[Field][]string{FieldWords: keywords, FieldTitle: keywords}}
+}
+
+func newTestService(t *testing.T, db DB, words Words, snapshots Snapshots) *Service {
+	t.Helper()
+	s, err := NewService(
+		slog.New(slog.NewTextHandler(io.Discard, nil)), db, words,
+		BM25{K1: 1.2, B: 0.75}, 10, Highlighter{Words: 3, PreTag: "<b>", PostTag: "</b>"}, snapshots,
+	)
+	if err != nil {
+		t.Fatalf("failed to create service: %v", err)
+	}
+	return s
+}
+
+// fakeDB keeps comics in memory, every change moves its update time by a second.
+type fakeDB struct {
+	comics  map[int]Comics
+	updated time.Time
+}
+
+func newFakeDB(comics ...Comics) *fakeDB {
+	db := &fakeDB{comics: make(map[int]Comics), updated: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)}
+	db.put(comics...)
+	return db
+}
+
+func (db *fakeDB) put(comics ...Comics) {
+	for _, c := range comics {
+		db.comics[c.ID] = c
+	}
+	db.updated = db.updated.Add(time.Second)
+}
+
+func (db *fakeDB) remove(IDs ...int) {
+	for _, ID := range IDs {
+		delete(db.comics, ID)
+	}
+	db.updated = db.updated.Add(time.Second)
+}
+
+func (db *fakeDB) sorted() []Comics {
+	return slices.SortedFunc(maps.Values(db.comics), func(a, b Comics) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+}
+
+func (db *fakeDB) Search(_ context.Context, field Field, keyword string) ([]Posting, error) {
+	var postings []Posting
+	for _, c := range db.sorted() {
+		keywords := c.Keywords[field]
+		p := Posting{ID: c.ID, Length: len(keywords)}
+		for i, k := range keywords {
+			if k == keyword {
+				p.Positions = append(p.Positions, i)
+			}
+		}
+		if p.TF = len(p.Positions); p.TF > 0 {
+			postings = append(postings, p)
+		}
+	}
+	return postings, nil
+}
+
+func (db *fakeDB) Get(_ context.Context, ID int) (Comics, error) {
+	c, ok := db.comics[ID]
+	if !ok {
+		return Comics{}, ErrNotFound
+	}
+	return c, nil
+}
+
+// List orders comics by ID whatever order is requested.
+func (db *fakeDB) List(_ context.Context, req ListRequest) ([]Comics, error) {
+	comics := db.sorted()
+	start := min(req.Offset, len(comics))
+	return comics[start:min(start+req.Limit, len(comics))], nil
+}
+
+func (db *fakeDB) GetMany(_ context.Context, IDs []int) ([]Comics, error) {
+	var comics []Comics
+	for _, ID := range IDs {
+		if c, ok := db.comics[ID]; ok {
+			comics = append(comics, c)
+		}
+	}
+	return comics, nil
+}
+
+func (db *fakeDB) All(context.Context) iter.Seq2[Comics, error] {
+	return func(yield func(Comics, error) bool) {
+		for _, c := range db.sorted() {
+			if !yield(c, nil) {
+				return
+			}
+		}
+	}
+}
+
+func (db *fakeDB) Published(_ context.Context, IDs []int) (map[int]time.Time, error) {
+	published := make(map[int]time.Time)
+	for _, ID := range IDs {
+		if c, ok := db.comics[ID]; ok && !c.Published.IsZero() {
+			published[ID] = c.Published
+		}
+	}
+	return published, nil
+}
+
+func (db *fakeDB) LastID(context.Context) (int, error) {
+	var lastID int
+	for ID := range db.comics {
+		lastID = max(lastID, ID)
+	}
+	return lastID, nil
+}
+
+func (db *fakeDB) Updated(context.Context) (time.Time, error) {
+	return db.updated, nil
+}
+
+func (db *fakeDB) Stats(context.Context) (map[Field]CorpusStats, error) {
+	stats := make(map[Field]CorpusStats)
+	for _, field := range append([]Field{FieldWords}, Fields...) {
+		s := CorpusStats{Documents: len(db.comics)}
+		var total int
+		for _, c := range db.comics {
+			total += len(c.Keywords[field])
+		}
+		if s.Documents > 0 {
+			s.AvgLength = float64(total) / float64(s.Documents)
+		}
+		stats[field] = s
+	}
+	return stats, nil
+}
+
+func (db *fakeDB) Expand(_ context.Context, field Field, pattern string, limit int) ([]string, error) {
+	counts := make(map[string]int) // keyword -> number of comics
+	for _, c := range db.comics {
+		for _, keyword := range slices.Compact(slices.Sorted(slices.Values(c.Keywords[field]))) {
+			if matchWildcard(pattern, keyword) {
+				counts[keyword]++
+			}
+		}
+	}
+	keywords := slices.SortedFunc(maps.Keys(counts), func(a, b string) int {
+		return cmp.Or(cmp.Compare(counts[b], counts[a]), strings.Compare(a, b))
+	})
+	return keywords[:min(limit, len(keywords))], nil
+}
+
+func (db *fakeDB) Keywords(_ context.Context, field Field, maxLength int) ([]string, error) {
+	unique := make(map[string]bool)
+	for _, c := range db.comics {
+		for _, keyword := range c.Keywords[field] {
+			if utf8.RuneCountInString(keyword) <= maxLength {
+				unique[keyword] = true
+			}
+		}
+	}
+	return slices.Sorted(maps.Keys(unique)), nil
+}
+
+// fakeWords takes lowercased words as their keywords unless stems are set.
+type fakeWords struct {
+	stems   map[string]string // lowercased word -> its keyword
+	calls   int               // to the words service
+	batches int               // of them analyzing many texts at once
+}
+
+func (w *fakeWords) analyze(phrase string) []Token {
+	var tokens []Token
+	for i, s := range split(phrase) {
+		text := phrase[s.start:s.end]
+		stem := strings.ToLower(text)
+		if other, ok := w.stems[stem]; ok {
+			stem = other
+		}
+		tokens = append(tokens, Token{Text: text, Stem: stem, Start: s.start, End: s.end, Position: i})
+	}
+	return tokens
+}
+
+func (w *fakeWords) Norm(_ context.Context, phrase string) ([]string, error) {
+	w.calls++
+	var keywords []string
+	for _, t := range w.analyze(phrase) {
+		if !slices.Contains(keywords, t.Stem) {
+			keywords = append(keywords, t.Stem)
+		}
+	}
+	return keywords, nil
+}
+
+func (w *fakeWords) Expand(context.Context, string) ([][]string, error) {
+	w.calls++
+	return nil, nil
+}
+
+func (w *fakeWords) Analyze(_ context.Context, phrase string) ([]Token, error) {
+	w.calls++
+	return w.analyze(phrase), nil
+}
+
+func (w *fakeWords) AnalyzeBatch(_ context.Context, phrases []string) ([][]Token, error) {
+	w.calls++
+	w.batches++
+	result := make([][]Token, 0, len(phrases))
+	for _, phrase := range phrases {
+		result = append(result, w.analyze(phrase))
+	}
+	return result, nil
+}
+
+// memorySnapshots keeps the last saved snapshot, err fails loading it.
+type memorySnapshots struct {
+	snapshot *IndexSnapshot
+	err      error
+}
+
+func (m *memorySnapshots) Save(snapshot IndexSnapshot) error {
+	m.snapshot = &snapshot
+	return nil
+}
+
+func (m *memorySnapshots) Load() (IndexSnapshot, error) {
+	switch {
+	case m.err != nil:
+		return IndexSnapshot{}, m.err
+	case m.snapshot == nil:
+		return IndexSnapshot{}, ErrNotFound
+	}
+	return *m.snapshot, nil
+}
@@ -0,0 +1,63 @@
+package core
+
+import "unicode/utf8"
+
+// minFuzzyPrefix is the shortest keyword allowed to match a prefix of a query keyword.
+const minFuzzyPrefix = 4
+
+// fuzziness returns the maximum edit distance allowed for a keyword:
+// short keywords must match exactly, long ones may have two typos.
+func fuzziness(keyword string) int {
+	switch n := utf8.RuneCountInString(keyword); {
+	case n < 3:
+		return 0
+	case n < 6:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// fuzzyWeight scales down relevance of a keyword found at distance d.
+func fuzzyWeight(d int) float64 {
+	return 1 / float64(1+d)
+}
+
+// fuzzyDistance returns edit distance between a misspelled query keyword
+// and an index keyword. A misspelled word often is not stemmed
+// ("recurion" stays as is while "recursion" becomes "recurs"), so
+// a keyword close to a prefix of the query matches too, one edit further.
+func fuzzyDistance(query, keyword string) int {
+	q, k := []rune(query), []rune(keyword)
+	d := distance(q, k)
+	if len(k) >= minFuzzyPrefix && len(k) < len(q) {
+		d = min(d, distance(q[:len(k)], k)+1)
+	}
+	return d
+}
+
+// distance is optimal string alignment distance:
+// insertions, deletions, substitutions and transpositions of adjacent runes.
+func distance(a, b []rune) int {
+	rows := make([][]int, len(a)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(b)+1)
+		rows[i][0] = i
+	}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			rows[i][j] = min(rows[i-1][j]+1, rows[i][j-1]+1, rows[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				rows[i][j] = min(rows[i][j], rows[i-2][j-2]+1)
+			}
+		}
+	}
+	return rows[len(a)][len(b)]
+}
@@ -0,0 +1,375 @@
+package core
+
+import (
+	"cmp"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// IndexUpdate is a change of DB to be applied to the index.
+// DB has no removal of single comics, comics of the update
+// missing in DB when it is applied are removed from the index.
+type IndexUpdate struct {
+	Cleaned   bool  // all comics are removed, applied before the added ones
+	Added     []int // IDs of new comics
+	Reindexed []int // IDs of comics with new keywords
+}
+
+// Index keeps an inverted index for the whole comics and for each of its fields.
+type Index struct {
+	fields      map[Field]*fieldIndex
+	published   map[int]time.Time // comics ID -> publication date if known
+	words       map[string]int    // lowercased word of comics text -> number of occurrences
+	suggestions []Suggestion      // sorted by text
+	forms       map[string]string // keyword -> the most frequent word normalized to it
+	lock        sync.RWMutex
+}
+
+type termFrequency struct {
+	keyword string
+	tf      int
+}
+
+type fieldIndex struct {
+	index   map[string][]Posting
+	lengths map[int]int
+	docs    map[int][]termFrequency // comics ID -> its unique keywords
+	total   int
+	terms   []string // dictionary of keywords, sorted lazily
+	sorted  bool
+}
+
+func newFieldIndex() *fieldIndex {
+	return &fieldIndex{
+		index:   make(map[string][]Posting),
+		lengths: make(map[int]int),
+		docs:    make(map[int][]termFrequency),
+	}
+}
+
+func newFieldIndexes() map[Field]*fieldIndex {
+	fields := map[Field]*fieldIndex{FieldWords: newFieldIndex()}
+	for _, field := range Fields {
+		fields[field] = newFieldIndex()
+	}
+	return fields
+}
+
+func NewIndex() *Index {
+	return &Index{
+		fields:    newFieldIndexes(),
+		published: make(map[int]time.Time),
+		words:     make(map[string]int),
+	}
+}
+
+func (i *Index) Clear() {
+	i.lock.Lock()
+	i.fields = newFieldIndexes()
+	i.published = make(map[int]time.Time)
+	i.words = make(map[string]int)
+	i.suggestions = nil
+	i.forms = nil
+	i.lock.Unlock()
+}
+
+// Put adds comics to the index replacing the previous version of it.
+// Words of comics text are counted to choose suggestions when it is put
+// first, the counts are not changed on replacement or removal, so they
+// are approximate until Clear.
+func (i *Index) Put(comics Comics) {
+	text := comics.Text()
+	spans := split(text)
+	i.lock.Lock()
+	if _, ok := i.fields[FieldWords].lengths[comics.ID]; !ok {
+		for _, s := range spans {
+			i.words[strings.ToLower(text[s.start:s.end])]++
+		}
+	}
+	for field, f := range i.fields {
+		f.remove(comics.ID)
+		f.put(comics.ID, comics.Keywords[field])
+	}
+	delete(i.published, comics.ID)
+	if !comics.Published.IsZero() {
+		i.published[comics.ID] = comics.Published
+	}
+	i.lock.Unlock()
+}
+
+func (i *Index) Remove(id int) {
+	i.lock.Lock()
+	for _, f := range i.fields {
+		f.remove(id)
+	}
+	delete(i.published, id)
+	i.lock.Unlock()
+}
+
+func (f *fieldIndex) put(id int, keywords []string) {
+	positions := make(map[string][]int, len(keywords))
+	for i, keyword := range keywords {
+		positions[keyword] = append(positions[keyword], i)
+	}
+	unique := make([]termFrequency, 0, len(positions))
+	for keyword, at := range positions {
+		if _, ok := f.index[keyword]; !ok {
+			f.terms = append(f.terms, keyword)
+			f.sorted = false
+		}
+		f.index[keyword] = append(f.index[keyword], Posting{ID: id, TF: len(at), Length: len(keywords), Positions: at})
+		unique = append(unique, termFrequency{keyword: keyword, tf: len(at)})
+	}
+	f.lengths[id] = len(keywords)
+	f.docs[id] = unique
+	f.total += len(keywords)
+}
+
+func (f *fieldIndex) remove(id int) {
+	length, ok := f.lengths[id]
+	if !ok {
+		return
+	}
+	for _, t := range f.docs[id] {
+		keyword := t.keyword
+		// postings may be shared with a snapshot, so they are copied
+		postings := slices.DeleteFunc(slices.Clone(f.index[keyword]), func(p Posting) bool {
+			return p.ID == id
+		})
+		if len(postings) > 0 {
+			f.index[keyword] = postings
+			continue
+		}
+		delete(f.index, keyword)
+		f.terms = slices.DeleteFunc(f.terms, func(term string) bool { return term == keyword })
+	}
+	delete(f.lengths, id)
+	delete(f.docs, id)
+	f.total -= length
+}
+
+func (i *Index) Get(field Field, keyword string) []Posting {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	return slices.Clone(i.fields[field].index[keyword])
+}
+
+// Published returns publication dates of comics, unknown dates are omitted.
+func (i *Index) Published(IDs []int) map[int]time.Time {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	result := make(map[int]time.Time, len(IDs))
+	for _, ID := range IDs {
+		if date, ok := i.published[ID]; ok {
+			result[ID] = date
+		}
+	}
+	return result
+}
+
+func (i *Index) Stats() map[Field]CorpusStats {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	result := make(map[Field]CorpusStats, len(i.fields))
+	for field, f := range i.fields {
+		stats := CorpusStats{Documents: len(f.lengths)}
+		if stats.Documents > 0 {
+			stats.AvgLength = float64(f.total) / float64(stats.Documents)
+		}
+		result[field] = stats
+	}
+	return result
+}
+
+// Related returns comics similar to comics ID by cosine similarity
+// of TF-IDF vectors of their keywords.
+func (i *Index) Related(ID int) (map[int]float64, error) {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	f := i.fields[FieldWords]
+	terms, ok := f.docs[ID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	idf := func(keyword string) float64 {
+		return math.Log(1 + float64(len(f.lengths))/float64(len(f.index[keyword])))
+	}
+	norm := func(terms []termFrequency) float64 {
+		var sum float64
+		for _, t := range terms {
+			w := float64(t.tf) * idf(t.keyword)
+			sum += w * w
+		}
+		return math.Sqrt(sum)
+	}
+
+	// dot products with all comics sharing keywords
+	similar := make(map[int]float64)
+	for _, t := range terms {
+		weight := idf(t.keyword)
+		for _, p := range f.index[t.keyword] {
+			if p.ID != ID {
+				similar[p.ID] += float64(t.tf*p.TF) * weight * weight
+			}
+		}
+	}
+	targetNorm := norm(terms)
+	for other, dot := range similar {
+		similar[other] = dot / (targetNorm * norm(f.docs[other]))
+	}
+	return similar, nil
+}
+
+// Expand returns up to limit keywords of field matching wildcard pattern,
+// the most frequent keywords go first.
+func (i *Index) Expand(field Field, pattern string, limit int) []string {
+	i.lock.RLock()
+	if f := i.fields[field]; f.sorted {
+		defer i.lock.RUnlock()
+		return f.expand(pattern, limit)
+	}
+	i.lock.RUnlock()
+
+	// terms are sorted and searched at once, so that a put in between
+	// does not leave them unsorted
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	f := i.fields[field]
+	if !f.sorted {
+		slices.Sort(f.terms)
+		f.sorted = true
+	}
+	return f.expand(pattern, limit)
+}
+
+func (f *fieldIndex) expand(pattern string, limit int) []string {
+	prefix := pattern
+	if pos := strings.IndexAny(pattern, "*?"); pos >= 0 {
+		prefix = pattern[:pos]
+	}
+	start, _ := slices.BinarySearch(f.terms, prefix)
+	var found []string
+	for _, term := range f.terms[start:] {
+		if !strings.HasPrefix(term, prefix) {
+			break
+		}
+		if matchWildcard(pattern, term) {
+			found = append(found, term)
+		}
+	}
+	slices.SortStableFunc(found, func(a, b string) int {
+		return cmp.Compare(len(f.index[b]), len(f.index[a])) // desc
+	})
+	if len(found) > limit {
+		found = found[:limit]
+	}
+	return found
+}
+
+// Keywords returns all keywords of field not longer than maxLength runes.
+func (i *Index) Keywords(field Field, maxLength int) []string {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	var found []string
+	for _, term := range i.fields[field].terms {
+		if utf8.RuneCountInString(term) <= maxLength {
+			found = append(found, term)
+		}
+	}
+	return found
+}
+
+// DBState identifies DB content the index is built from,
+// the index is up to date while DB stays in the same state.
+type DBState struct {
+	LastID  int
+	Updated time.Time
+}
+
+// IndexSnapshot is the index content stored between restarts.
+type IndexSnapshot struct {
+	Version   int
+	LastID    int       // last comics ID in DB when the index was built
+	Updated   time.Time // when comics in DB were last changed then
+	Fields    map[Field]FieldSnapshot
+	Published map[int]time.Time
+	Words     map[string]int
+}
+
+type FieldSnapshot struct {
+	Postings map[string][]Posting
+	Lengths  map[int]int
+}
+
+// Documents returns number of comics in the snapshot.
+func (s IndexSnapshot) Documents() int {
+	return len(s.Fields[FieldWords].Lengths)
+}
+
+// indexVersion must be incremented whenever the index layout changes,
+// so that snapshots of the old layout are rebuilt.
+const indexVersion = 6
+
+func (i *Index) Snapshot(state DBState) IndexSnapshot {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	snapshot := IndexSnapshot{
+		Version:   indexVersion,
+		LastID:    state.LastID,
+		Updated:   state.Updated,
+		Fields:    make(map[Field]FieldSnapshot, len(i.fields)),
+		Published: maps.Clone(i.published),
+		Words:     maps.Clone(i.words),
+	}
+	for field, f := range i.fields {
+		snapshot.Fields[field] = FieldSnapshot{
+			Postings: maps.Clone(f.index),
+			Lengths:  maps.Clone(f.lengths),
+		}
+	}
+	return snapshot
+}
+
+func (i *Index) Restore(snapshot IndexSnapshot) {
+	fields := newFieldIndexes()
+	for field, f := range fields {
+		s := snapshot.Fields[field]
+		if s.Postings != nil {
+			f.index = s.Postings
+		}
+		if s.Lengths != nil {
+			f.lengths = s.Lengths
+		}
+		for _, length := range f.lengths {
+			f.total += length
+		}
+		f.terms = slices.Sorted(maps.Keys(f.index))
+		f.sorted = true
+		for _, term := range f.terms {
+			for _, p := range f.index[term] {
+				f.docs[p.ID] = append(f.docs[p.ID], termFrequency{keyword: term, tf: p.TF})
+			}
+		}
+	}
+	published := snapshot.Published
+	if published == nil {
+		published = make(map[int]time.Time)
+	}
+	words := snapshot.Words
+	if words == nil {
+		words = make(map[string]int)
+	}
+	i.lock.Lock()
+	i.fields = fields
+	i.published = published
+	i.words = words
+	i.suggestions = nil
+	i.forms = nil
+	i.lock.Unlock()
+}
@@ -1,44 +1,170 @@
 package core
 
 import (
-	"slices"
-	"sync"
+	"fmt"
+	"strings"
+	"time"
 )
 
-type Comics struct {
-	ID       int
-	URL      string
-	Keywords []string
-	Score    int
+type SearchRequest struct {
+	Phrase    string
+	Limit     int
+	Offset    int
+	PageToken string
+	Fuzzy     bool
+	Filter    Filter
+	// AutoCorrect runs the suggested query when the original one finds nothing.
+	AutoCorrect bool
+}
+
+func (r SearchRequest) validate() error {
+	if r.Limit < 0 || r.Offset < 0 {
+		return fmt.Errorf("%w: negative limit or offset", ErrBadArguments)
+	}
+	if r.Offset > 0 && r.PageToken != "" {
+		return fmt.Errorf("%w: offset and page token are mutually exclusive", ErrBadArguments)
+	}
+	return r.Filter.validate()
+}
+
+// Filter restricts search to comics published from From to To
+// with IDs from MinID to MaxID inclusive, zero values do not restrict.
+type Filter struct {
+	From  time.Time
+	To    time.Time
+	MinID int
+	MaxID int
+}
+
+func (f Filter) validate() error {
+	if f.MinID < 0 || f.MaxID < 0 {
+		return fmt.Errorf("%w: negative ID range", ErrBadArguments)
+	}
+	if f.MaxID > 0 && f.MinID > f.MaxID {
+		return fmt.Errorf("%w: empty ID range", ErrBadArguments)
+	}
+	if !f.From.IsZero() && !f.To.IsZero() && f.From.After(f.To) {
+		return fmt.Errorf("%w: empty date range", ErrBadArguments)
+	}
+	return nil
+}
+
+func (f Filter) byDate() bool {
+	return !f.From.IsZero() || !f.To.IsZero()
+}
+
+func (f Filter) matchID(ID int) bool {
+	return ID >= f.MinID && (f.MaxID == 0 || ID <= f.MaxID)
+}
+
+// matchDate reports whether comics published at date passes the filter,
+// comics with unknown date never pass a date filter.
+func (f Filter) matchDate(date time.Time) bool {
+	if date.IsZero() {
+		return false
+	}
+	return !date.Before(f.From) && (f.To.IsZero() || !date.After(f.To))
 }
 
-type Index struct {
-	index map[string][]int
-	lock  sync.RWMutex
+type SearchResult struct {
+	Comics        []Comics
+	Total         int // number of all comics matching the query
+	NextPageToken string
+	Suggestion    string // corrected query if the original one finds nothing
+	Corrected     bool   // comics are found by the suggestion
 }
 
-func NewIndex() *Index {
-	return &Index{
-		index: make(map[string][]int),
+type ListOrder int
+
+const (
+	ListByID ListOrder = iota
+	ListByIDDesc
+	ListByPublished // comics with unknown date go last
+	ListByPublishedDesc
+	ListByKeywords // number of keywords
+	ListByKeywordsDesc
+)
+
+type ListRequest struct {
+	Limit  int
+	Offset int
+	Order  ListOrder
+}
+
+func (r ListRequest) validate() error {
+	if r.Limit < 0 || r.Offset < 0 {
+		return fmt.Errorf("%w: negative limit or offset", ErrBadArguments)
 	}
+	if r.Order < ListByID || r.Order > ListByKeywordsDesc {
+		return fmt.Errorf("%w: unknown order %d", ErrBadArguments, r.Order)
+	}
+	return nil
 }
 
-func (i *Index) Clear() {
-	i.lock.Lock()
-	i.index = make(map[string][]int)
-	i.lock.Unlock()
+type ListResult struct {
+	Comics []Comics
+	Total  int // number of all comics
 }
 
-func (i *Index) Put(id int, keywords []string) {
-	i.lock.Lock()
-	for _, keyword := range keywords {
-		i.index[keyword] = append(i.index[keyword], id)
+// Field is a part of comics indexed separately.
+type Field string
+
+const (
+	FieldWords      Field = "words" // the whole comics
+	FieldTitle      Field = "title"
+	FieldAlt        Field = "alt"
+	FieldTranscript Field = "transcript"
+)
+
+// Fields can be searched in separately with field:term queries.
+var Fields = []Field{FieldTitle, FieldAlt, FieldTranscript}
+
+// orWords returns the field keywords are looked up in, all keywords if no field is set.
+func (f Field) orWords() Field {
+	if f == "" {
+		return FieldWords
 	}
-	i.lock.Unlock()
+	return f
+}
+
+type Comics struct {
+	ID         int
+	URL        string
+	Keywords   map[Field][]string
+	Title      string
+	SafeTitle  string
+	Alt        string
+	Transcript string
+	Published  time.Time // zero if unknown
+	Permalink  string
+	Score      float64
+	Snippet    string
+}
+
+// Text is comics text snippets are taken from.
+func (c Comics) Text() string {
+	return strings.Join([]string{c.Title, c.Alt, c.Transcript}, "\n")
+}
+
+// Posting is an occurrence of a keyword in a comics.
+type Posting struct {
+	ID        int
+	TF        int   // keyword frequency in the comics
+	Length    int   // total number of keywords in the comics
+	Positions []int // of the keyword among keywords of the comics, ascending
+}
+
+// Token is a word of a text with its keyword.
+type Token struct {
+	Text     string
+	Stem     string
+	Start    int // byte offsets in the text
+	End      int
+	Position int
+	Stop     bool // stop words have no keywords
 }
 
-func (i *Index) Get(keyword string) []int {
-	i.lock.RLock()
-	defer i.lock.RUnlock()
-	return slices.Clone(i.index[keyword])
+type CorpusStats struct {
+	Documents int
+	AvgLength float64
 }
@@ -2,20 +2,47 @@ package core
 
 import (
 	"context"
+	"iter"
+	"time"
 )
 
 type Searcher interface {
-	Search(ctx context.Context, phrase string, limit int) ([]Comics, error)
-	SearchIndex(ctx context.Context, phrase string, limit int) ([]Comics, error)
+	Search(ctx context.Context, req SearchRequest) (SearchResult, error)
+	SearchIndex(ctx context.Context, req SearchRequest) (SearchResult, error)
+	Get(ctx context.Context, ID int) (Comics, error)
+	List(ctx context.Context, req ListRequest) (ListResult, error)
+	Related(ctx context.Context, ID, limit int) (SearchResult, error)
+	Suggest(ctx context.Context, prefix string, limit int) ([]Suggestion, error)
 	BuildIndex(ctx context.Context) error
+	RestoreIndex(ctx context.Context) error
+	UpdateIndex(ctx context.Context, update IndexUpdate) error
 }
 
 type DB interface {
-	Search(ctx context.Context, keyword string) ([]int, error)
+	Search(ctx context.Context, field Field, keyword string) ([]Posting, error)
 	Get(ctx context.Context, ID int) (Comics, error)
+	List(ctx context.Context, req ListRequest) ([]Comics, error)
+	GetMany(ctx context.Context, IDs []int) ([]Comics, error)
+	All(ctx context.Context) iter.Seq2[Comics, error]
+	Published(ctx context.Context, IDs []int) (map[int]time.Time, error)
 	LastID(ctx context.Context) (int, error)
+	// Updated returns when comics were last added or changed, zero time if there are none.
+	Updated(ctx context.Context) (time.Time, error)
+	Stats(ctx context.Context) (map[Field]CorpusStats, error)
+	Expand(ctx context.Context, field Field, pattern string, limit int) ([]string, error)
+	Keywords(ctx context.Context, field Field, maxLength int) ([]string, error)
 }
 
 type Words interface {
 	Norm(ctx context.Context, phrase string) ([]string, error)
+	// Expand returns synonyms of phrase, each synonym is a list of keywords.
+	Expand(ctx context.Context, phrase string) ([][]string, error)
+	Analyze(ctx context.Context, phrase string) ([]Token, error)
+	// AnalyzeBatch returns tokens of phrases in the same order.
+	AnalyzeBatch(ctx context.Context, phrases []string) ([][]Token, error)
+}
+
+type Snapshots interface {
+	Save(snapshot IndexSnapshot) error
+	Load() (IndexSnapshot, error)
 }
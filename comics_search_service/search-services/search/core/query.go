@@ -0,0 +1,404 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Query language:
+//
+//	query   := orExpr
+//	orExpr  := andExpr ( [OR] andExpr )*
+//	andExpr := unary ( AND unary )*
+//	unary   := ( NOT | - ) unary | primary
+//	primary := '(' orExpr ')' | '"' phrase '"' | term
+//
+// A term or a phrase prefixed by a field name and a colon, like
+// title:python or alt:"regular expressions", is searched in that
+// field only. Adjacent clauses without an operator are OR-ed. Negated clauses
+// exclude comics from the group they belong to, so every group
+// needs at least one positive clause. Terms with * (any number of
+// characters) or ? (exactly one character) are wildcards matched
+// against index keywords as is, without normalization. Keywords of a phrase
+// must follow each other in the order of its words, stop words aside.
+// Other terms and phrases match their synonyms too.
+
+type node interface {
+	node()
+}
+
+type termNode struct {
+	field    Field // empty for any field
+	text     string
+	phrase   bool
+	wildcard bool
+	stems    []string   // of a phrase in order of its words, repeated ones included
+	synonyms [][]string // alternatives to stems
+}
+
+type operator int
+
+const (
+	opOr operator = iota
+	opAnd
+)
+
+type boolNode struct {
+	op       operator
+	clauses  []node
+	excludes []node
+}
+
+func (*termNode) node() {}
+func (*boolNode) node() {}
+
+func (b *boolNode) add(n node, negated bool) {
+	if negated {
+		b.excludes = append(b.excludes, n)
+		return
+	}
+	b.clauses = append(b.clauses, n)
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokWord
+	tokPhrase
+	tokAnd
+	tokOr
+	tokNot
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	field Field
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of query"
+	case tokPhrase:
+		return prefix(t.field) + `"` + t.text + `"`
+	}
+	return prefix(t.field) + t.text
+}
+
+func prefix(field Field) string {
+	if field == "" {
+		return ""
+	}
+	return string(field) + ":"
+}
+
+func badQuery(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrBadArguments, fmt.Sprintf(format, args...))
+}
+
+func lex(query string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(query); {
+		r, size := utf8.DecodeRuneInString(query[i:])
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+		case r == '(':
+			tokens = append(tokens, token{kind: tokLParen, text: "("})
+			i += size
+		case r == ')':
+			tokens = append(tokens, token{kind: tokRParen, text: ")"})
+			i += size
+		case r == '"':
+			phrase, n, err := lexPhrase(query, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokPhrase, text: phrase})
+			i += n
+		case r == '-' && negates(query[i+size:]):
+			tokens = append(tokens, token{kind: tokNot, text: "-"})
+			i += size
+		default:
+			end := strings.IndexFunc(query[i:], func(r rune) bool {
+				return unicode.IsSpace(r) || r == '(' || r == ')' || r == '"'
+			})
+			if end < 0 {
+				end = len(query) - i
+			}
+			word := query[i : i+end]
+			if field, text, ok := fieldPrefix(word); ok {
+				i += end
+				if text != "" {
+					tokens = append(tokens, token{kind: tokWord, text: text, field: field})
+					continue
+				}
+				if !strings.HasPrefix(query[i:], `"`) {
+					return nil, badQuery("no term after %s:", field)
+				}
+				phrase, n, err := lexPhrase(query, i)
+				if err != nil {
+					return nil, err
+				}
+				tokens = append(tokens, token{kind: tokPhrase, text: phrase, field: field})
+				i += n
+				continue
+			}
+			switch word {
+			case "AND":
+				tokens = append(tokens, token{kind: tokAnd, text: word})
+			case "OR":
+				tokens = append(tokens, token{kind: tokOr, text: word})
+			case "NOT":
+				tokens = append(tokens, token{kind: tokNot, text: word})
+			default:
+				tokens = append(tokens, token{kind: tokWord, text: word})
+			}
+			i += end
+		}
+	}
+	return append(tokens, token{kind: tokEOF}), nil
+}
+
+// lexPhrase returns text of a quoted phrase starting at position i
+// and the number of bytes it takes with quotes.
+func lexPhrase(query string, i int) (string, int, error) {
+	end := strings.IndexRune(query[i+1:], '"')
+	if end < 0 {
+		return "", 0, badQuery("unterminated phrase at position %d", i)
+	}
+	return query[i+1 : i+1+end], end + 2, nil
+}
+
+// fieldPrefix splits a field:term word, words with other prefixes are plain terms.
+func fieldPrefix(word string) (Field, string, bool) {
+	name, text, ok := strings.Cut(word, ":")
+	if !ok {
+		return "", "", false
+	}
+	for _, field := range Fields {
+		if name == string(field) {
+			return field, text, true
+		}
+	}
+	return "", "", false
+}
+
+// negates reports whether minus followed by rest is a negation
+// rather than a punctuation like "->".
+func negates(rest string) bool {
+	r, _ := utf8.DecodeRuneInString(rest)
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '(' || r == '"'
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func parseQuery(query string) (node, error) {
+	tokens, err := lex(query)
+	if err != nil {
+		return nil, err
+	}
+	p := parser{tokens: tokens}
+	n, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, badQuery("unexpected %s", t)
+	}
+	return n, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) parseOr() (node, error) {
+	group := &boolNode{op: opOr}
+	for {
+		n, negated, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		group.add(n, negated)
+
+		switch p.peek().kind {
+		case tokEOF, tokRParen:
+			if len(group.clauses) == 0 {
+				return nil, badQuery("query has no positive terms")
+			}
+			if len(group.clauses) == 1 && len(group.excludes) == 0 {
+				return group.clauses[0], nil
+			}
+			return group, nil
+		case tokOr:
+			p.next()
+		}
+	}
+}
+
+func (p *parser) parseAnd() (node, bool, error) {
+	first, negated, err := p.parseUnary()
+	if err != nil {
+		return nil, false, err
+	}
+	if p.peek().kind != tokAnd {
+		return first, negated, nil
+	}
+	group := &boolNode{op: opAnd}
+	group.add(first, negated)
+	for p.peek().kind == tokAnd {
+		p.next()
+		n, negated, err := p.parseUnary()
+		if err != nil {
+			return nil, false, err
+		}
+		group.add(n, negated)
+	}
+	if len(group.clauses) == 0 {
+		return nil, false, badQuery("AND group has no positive terms")
+	}
+	return group, false, nil
+}
+
+func (p *parser) parseUnary() (node, bool, error) {
+	if p.peek().kind == tokNot {
+		p.next()
+		n, negated, err := p.parseUnary()
+		return n, !negated, err
+	}
+	n, err := p.parsePrimary()
+	return n, false, err
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokLParen:
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokRParen {
+			return nil, badQuery("expected ) instead of %s", closing)
+		}
+		return n, nil
+	case tokPhrase:
+		if strings.TrimSpace(t.text) == "" {
+			return nil, badQuery("empty phrase")
+		}
+		return &termNode{field: t.field, text: t.text, phrase: true}, nil
+	case tokWord:
+		return &termNode{field: t.field, text: t.text, wildcard: strings.ContainsAny(t.text, "*?")}, nil
+	}
+	return nil, badQuery("unexpected %s", t)
+}
+
+// prune removes terms without keywords, e.g. consisting of stop words only.
+func prune(n node) node {
+	switch n := n.(type) {
+	case *termNode:
+		if len(n.stems) == 0 {
+			return nil
+		}
+		return n
+	case *boolNode:
+		clauses := n.clauses[:0]
+		for _, c := range n.clauses {
+			if c = prune(c); c != nil {
+				clauses = append(clauses, c)
+			}
+		}
+		excludes := n.excludes[:0]
+		for _, c := range n.excludes {
+			if c = prune(c); c != nil {
+				excludes = append(excludes, c)
+			}
+		}
+		if len(clauses) == 0 {
+			return nil
+		}
+		n.clauses, n.excludes = clauses, excludes
+		return n
+	}
+	return n
+}
+
+func terms(n node) []*termNode {
+	switch n := n.(type) {
+	case *termNode:
+		return []*termNode{n}
+	case *boolNode:
+		var result []*termNode
+		for _, c := range n.clauses {
+			result = append(result, terms(c)...)
+		}
+		for _, c := range n.excludes {
+			result = append(result, terms(c)...)
+		}
+		return result
+	}
+	return nil
+}
+
+// wildcardPattern lowercases pattern and drops punctuation the way normalization does.
+func wildcardPattern(text string) (string, error) {
+	var literal bool
+	pattern := strings.Map(func(r rune) rune {
+		switch {
+		case r == '*' || r == '?':
+			return r
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			literal = true
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, text)
+	if !literal {
+		return "", badQuery("wildcard %q has no letters", text)
+	}
+	return pattern, nil
+}
+
+func matchWildcard(pattern, s string) bool {
+	p, str := []rune(pattern), []rune(s)
+	// position of the last * and the string position it matched up to
+	star, matched := -1, 0
+	var i, j int
+	for j < len(str) {
+		switch {
+		case i < len(p) && (p[i] == '?' || p[i] == str[j]):
+			i++
+			j++
+		case i < len(p) && p[i] == '*':
+			star, matched = i, j
+			i++
+		case star >= 0:
+			matched++
+			i, j = star+1, matched
+		default:
+			return false
+		}
+	}
+	for i < len(p) && p[i] == '*' {
+		i++
+	}
+	return i == len(p)
+}
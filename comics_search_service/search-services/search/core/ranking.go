@@ -0,0 +1,47 @@
+package core
+
+import (
+	"fmt"
+	"math"
+)
+
+// BM25 is Okapi BM25 ranking function parameters.
+// K1 controls keyword frequency saturation, B controls comics length normalization.
+// Boosts weight keywords found in separate fields, they are added
+// to the relevance of the comics computed by all its keywords.
+type BM25 struct {
+	K1     float64
+	B      float64
+	Boosts map[Field]float64
+}
+
+func (r BM25) validate() error {
+	if r.K1 < 0 {
+		return fmt.Errorf("wrong bm25 k1 specified: %v", r.K1)
+	}
+	if r.B < 0 || r.B > 1 {
+		return fmt.Errorf("wrong bm25 b specified: %v", r.B)
+	}
+	for field, boost := range r.Boosts {
+		if boost < 0 {
+			return fmt.Errorf("wrong %s boost specified: %v", field, boost)
+		}
+	}
+	return nil
+}
+
+// IDF returns inverse document frequency of a keyword found in df comics.
+func (r BM25) IDF(df int, stats CorpusStats) float64 {
+	n := float64(stats.Documents)
+	return math.Log(1 + (n-float64(df)+0.5)/(float64(df)+0.5))
+}
+
+// Score returns contribution of a single keyword posting to the comics relevance.
+func (r BM25) Score(p Posting, idf float64, stats CorpusStats) float64 {
+	norm := 1.0
+	if stats.AvgLength > 0 {
+		norm = 1 - r.B + r.B*float64(p.Length)/stats.AvgLength
+	}
+	tf := float64(p.TF)
+	return idf * tf * (r.K1 + 1) / (tf + r.K1*norm)
+}
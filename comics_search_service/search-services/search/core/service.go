@@ -1,103 +1,333 @@
 package core
 
 import (
-	"cmp"
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
 	"maps"
 	"slices"
+	"sync"
 )
 
 type Service struct {
-	log   *slog.Logger
-	db    DB
-	words Words
-	index *Index
+	log           *slog.Logger
+	db            DB
+	words         Words
+	index         *Index
+	ranker        BM25
+	maxExpansions int
+	highlighter   Highlighter
+	snapshots     Snapshots
+	stems         map[string]string // lowercased word of comics text in the index -> its keyword
+	stemsLock     sync.Mutex
 }
 
-func NewService(log *slog.Logger, db DB, words Words) (*Service, error) {
-
+func NewService(
+	log *slog.Logger, db DB, words Words,
+	ranker BM25, maxExpansions int, highlighter Highlighter, snapshots Snapshots,
+) (*Service, error) {
+	if err := ranker.validate(); err != nil {
+		return nil, err
+	}
+	if err := highlighter.validate(); err != nil {
+		return nil, err
+	}
+	if maxExpansions < 1 {
+		return nil, fmt.Errorf("wrong max expansions specified: %d", maxExpansions)
+	}
 	return &Service{
-		log:   log,
-		db:    db,
-		words: words,
-		index: NewIndex(),
+		log:           log,
+		db:            db,
+		words:         words,
+		index:         NewIndex(),
+		ranker:        ranker,
+		maxExpansions: maxExpansions,
+		highlighter:   highlighter,
+		snapshots:     snapshots,
+		stems:         make(map[string]string),
 	}, nil
 }
 
-func (s *Service) Search(ctx context.Context, phrase string, limit int) ([]Comics, error) {
+func (s *Service) Search(ctx context.Context, req SearchRequest) (SearchResult, error) {
+
+	if err := req.validate(); err != nil {
+		return SearchResult{}, err
+	}
 
-	keywords, err := s.words.Norm(ctx, phrase)
+	query, err := s.parse(ctx, req.Phrase)
 	if err != nil {
-		s.log.Error("failed to find keywords", "error", err)
-		return nil, err
+		return SearchResult{}, err
+	}
+
+	stats, err := s.db.Stats(ctx)
+	if err != nil {
+		s.log.Error("failed to get corpus stats from DB", "error", err)
+		return SearchResult{}, err
+	}
+
+	found, matched, err := s.evaluate(ctx, query, stats, dbSource{db: s.db}, req)
+	if err != nil {
+		s.log.Error("failed to search keyword in DB", "error", err)
+		return SearchResult{}, err
+	}
+	if len(found) == 0 {
+		return s.suggest(ctx, req, stats, dbSource{db: s.db})
+	}
+
+	return s.fetch(ctx, found, matched, req)
+}
+
+func (s *Service) SearchIndex(ctx context.Context, req SearchRequest) (SearchResult, error) {
+
+	if err := req.validate(); err != nil {
+		return SearchResult{}, err
+	}
+
+	query, err := s.parse(ctx, req.Phrase)
+	if err != nil {
+		return SearchResult{}, err
+	}
+
+	found, matched, err := s.evaluate(ctx, query, s.index.Stats(), indexSource{index: s.index}, req)
+	if err != nil {
+		return SearchResult{}, err
 	}
-	s.log.Debug("normalized query", "keywords", keywords)
+	if len(found) == 0 {
+		return s.suggest(ctx, req, s.index.Stats(), indexSource{index: s.index})
+	}
+
+	return s.fetch(ctx, found, matched, req)
+}
 
-	// comics ID -> number of findings
-	scores := map[int]int{}
-	for _, keyword := range keywords {
-		IDs, err := s.db.Search(ctx, keyword)
+// suggest corrects a query which found nothing in the source, the corrected query
+// is suggested only if it finds something there and is run if requested.
+// Words are corrected by the index vocabulary for both sources, the index is built from DB.
+// A query found nothing has no comics and no error, as a search of an empty page.
+func (s *Service) suggest(
+	ctx context.Context, req SearchRequest, stats map[Field]CorpusStats, src source,
+) (SearchResult, error) {
+	phrase, err := s.correct(ctx, req.Phrase)
+	if err != nil {
+		s.log.Error("failed to correct query", "phrase", req.Phrase, "error", err)
+		return SearchResult{}, err
+	}
+	if phrase == req.Phrase {
+		return SearchResult{Comics: []Comics{}}, nil
+	}
+	corrected := req
+	corrected.Phrase = phrase
+	query, err := s.parse(ctx, phrase)
+	if err != nil {
+		return SearchResult{}, err
+	}
+	found, matched, err := s.evaluate(ctx, query, stats, src, corrected)
+	if err != nil {
+		return SearchResult{}, err
+	}
+	if len(found) == 0 {
+		return SearchResult{Comics: []Comics{}}, nil
+	}
+	s.log.Debug("suggested query", "phrase", req.Phrase, "suggestion", phrase)
+	if !req.AutoCorrect {
+		return SearchResult{Comics: []Comics{}, Suggestion: phrase}, nil
+	}
+	result, err := s.fetch(ctx, found, matched, corrected)
+	if err != nil {
+		return SearchResult{}, err
+	}
+	result.Suggestion = phrase
+	result.Corrected = true
+	return result, nil
+}
+
+func (s *Service) Get(ctx context.Context, ID int) (Comics, error) {
+	comics, err := s.db.Get(ctx, ID)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		s.log.Error("failed to fetch comics", "id", ID, "error", err)
+	}
+	return comics, err
+}
+
+func (s *Service) List(ctx context.Context, req ListRequest) (ListResult, error) {
+	if err := req.validate(); err != nil {
+		return ListResult{}, err
+	}
+	comics, err := s.db.List(ctx, req)
+	if err != nil {
+		s.log.Error("failed to list comics", "error", err)
+		return ListResult{}, err
+	}
+	stats, err := s.db.Stats(ctx)
+	if err != nil {
+		s.log.Error("failed to get corpus stats from DB", "error", err)
+		return ListResult{}, err
+	}
+	return ListResult{Comics: comics, Total: stats[FieldWords].Documents}, nil
+}
+
+// Related returns comics with keywords most similar to the keywords of comics ID.
+func (s *Service) Related(ctx context.Context, ID, limit int) (SearchResult, error) {
+	if limit < 0 {
+		return SearchResult{}, fmt.Errorf("%w: negative limit", ErrBadArguments)
+	}
+	similar, err := s.index.Related(ID)
+	if err != nil {
+		return SearchResult{}, err
+	}
+	sorted := slices.SortedFunc(maps.Keys(similar), func(a, b int) int {
+		return compareResults(similar[a], a, similar[b], b)
+	})
+	result := SearchResult{Total: len(sorted)}
+	result.Comics, err = s.db.GetMany(ctx, sorted[:min(limit, len(sorted))])
+	if err != nil {
+		s.log.Error("failed to fetch comics", "error", err)
+		return SearchResult{}, err
+	}
+	for i := range result.Comics {
+		result.Comics[i].Score = similar[result.Comics[i].ID]
+	}
+	return result, nil
+}
+
+// parse builds query tree with normalized keywords, nil query means nothing to search.
+func (s *Service) parse(ctx context.Context, phrase string) (node, error) {
+	query, err := parseQuery(phrase)
+	if err != nil {
+		s.log.Debug("failed to parse query", "phrase", phrase, "error", err)
+		return nil, err
+	}
+	for _, term := range terms(query) {
+		if term.wildcard {
+			pattern, err := wildcardPattern(term.text)
+			if err != nil {
+				return nil, err
+			}
+			term.stems = []string{pattern}
+			continue
+		}
+		if term.phrase {
+			term.stems, err = s.phraseKeywords(ctx, term.text)
+		} else {
+			term.stems, err = s.words.Norm(ctx, term.text)
+		}
 		if err != nil {
-			s.log.Error("failed to search keyword in DB", "error", err)
+			s.log.Error("failed to find keywords", "error", err)
 			return nil, err
 		}
-		for _, ID := range IDs {
-			scores[ID]++
+		if len(term.stems) > 0 {
+			term.synonyms, err = s.words.Expand(ctx, term.text)
+			if err != nil {
+				s.log.Error("failed to find synonyms", "error", err)
+				return nil, err
+			}
 		}
+		s.log.Debug("normalized term", "term", term.text, "keywords", term.stems, "synonyms", term.synonyms)
 	}
-
-	return s.fetch(ctx, scores, limit)
+	return prune(query), nil
 }
 
-func (s *Service) SearchIndex(ctx context.Context, phrase string, limit int) ([]Comics, error) {
-
-	keywords, err := s.words.Norm(ctx, phrase)
+// phraseKeywords returns keywords of phrase in order of its words.
+func (s *Service) phraseKeywords(ctx context.Context, phrase string) ([]string, error) {
+	tokens, err := s.words.Analyze(ctx, phrase)
 	if err != nil {
-		s.log.Error("failed to find keywords", "error", err)
 		return nil, err
 	}
-	s.log.Debug("normalized query", "keywords", keywords)
+	var keywords []string
+	for _, t := range tokens {
+		if !t.Stop {
+			keywords = append(keywords, t.Stem)
+		}
+	}
+	return keywords, nil
+}
 
-	// comics ID -> number of findings
-	scores := map[int]int{}
-	for _, keyword := range keywords {
+// evaluate returns relevance of found comics passing the filter and keywords they were found by.
+func (s *Service) evaluate(
+	ctx context.Context, query node, stats map[Field]CorpusStats, source source, req SearchRequest,
+) (scores, map[string]bool, error) {
+	if query == nil {
+		return scores{}, nil, nil
+	}
+	e := newEvaluator(s.ranker, stats, source, s.maxExpansions, req.Fuzzy)
+	found, err := e.eval(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := filter(ctx, found, source, req.Filter); err != nil {
+		return nil, nil, err
+	}
+	return found, e.matched, nil
+}
 
-		for _, ID := range s.index.Get(keyword) {
-			scores[ID]++
+func filter(ctx context.Context, found scores, source source, f Filter) error {
+	for ID := range found {
+		if !f.matchID(ID) {
+			delete(found, ID)
 		}
 	}
-
-	return s.fetch(ctx, scores, limit)
+	if !f.byDate() || len(found) == 0 {
+		return nil
+	}
+	published, err := source.published(ctx, slices.Collect(maps.Keys(found)))
+	if err != nil {
+		return err
+	}
+	for ID := range found {
+		if !f.matchDate(published[ID]) {
+			delete(found, ID)
+		}
+	}
+	return nil
 }
 
-func (s *Service) fetch(ctx context.Context, scores map[int]int, limit int) ([]Comics, error) {
-	s.log.Debug("relevant comics", "count", len(scores))
+func (s *Service) fetch(
+	ctx context.Context, found scores, matched map[string]bool, req SearchRequest,
+) (SearchResult, error) {
+	s.log.Debug("relevant comics", "count", len(found))
 
-	// sort by number of findings
-	sorted := slices.SortedFunc(maps.Keys(scores), func(a, b int) int {
-		return cmp.Compare(scores[b], scores[a]) // desc
+	// sort by relevance
+	sorted := slices.SortedFunc(maps.Keys(found), func(a, b int) int {
+		return compareResults(found[a], a, found[b], b)
 	})
 
-	// limit results
-	if len(sorted) < limit {
-		limit = len(sorted)
+	// skip previous pages
+	start := req.Offset
+	if req.PageToken != "" {
+		after, err := decodeCursor(req.PageToken, req)
+		if err != nil {
+			return SearchResult{}, err
+		}
+		start, _ = slices.BinarySearchFunc(sorted, after, func(ID int, after cursor) int {
+			return compareResults(found[ID], ID, after.Score, after.ID)
+		})
+		if start < len(sorted) && sorted[start] == after.ID {
+			start++
+		}
 	}
-	sorted = sorted[:limit]
+	start = min(start, len(sorted))
+	end := min(start+req.Limit, len(sorted))
 
 	// fetch comics
-	result := make([]Comics, 0, len(sorted))
-	for _, ID := range sorted {
-		comics, err := s.db.Get(ctx, ID)
-		if err != nil {
-			s.log.Error("failed to fetch comics", "id", ID, "error", err)
-			return nil, err
-		}
-		comics.Score = scores[ID]
-		result = append(result, comics)
+	result := SearchResult{
+		Comics: make([]Comics, 0, end-start),
+		Total:  len(sorted),
+	}
+	comics, err := s.db.GetMany(ctx, sorted[start:end])
+	if err != nil {
+		s.log.Error("failed to fetch comics", "error", err)
+		return SearchResult{}, err
+	}
+	for _, c := range comics {
+		c.Score = found[c.ID]
+		result.Comics = append(result.Comics, c)
 	}
-	s.log.Debug("returning comics", "count", len(result))
+	s.highlight(ctx, result.Comics, matched)
+	if end < len(sorted) && end > start {
+		last := sorted[end-1]
+		result.NextPageToken = encodeCursor(cursor{Query: req.fingerprint(), Score: found[last], ID: last})
+	}
+	s.log.Debug("returning comics", "count", len(result.Comics), "total", result.Total)
 
 	return result, nil
 }
@@ -105,24 +335,118 @@ func (s *Service) fetch(ctx context.Context, scores map[int]int, limit int) ([]C
 func (s *Service) BuildIndex(ctx context.Context) error {
 
 	s.index.Clear()
-	lastID, err := s.db.LastID(ctx)
+	// the state is got before comics, so that a change while building makes the snapshot stale
+	state, err := s.dbState(ctx)
 	if err != nil {
 		return err
 	}
 	var comicsCount int
-	for ID := 1; ID <= lastID; ID++ {
-		comics, err := s.db.Get(ctx, ID)
+	for comics, err := range s.db.All(ctx) {
 		if err != nil {
-			if errors.Is(err, ErrNotFound) {
-				continue
-			}
-			s.log.Error("failed to fetch comics", "id", ID, "error", err)
+			s.log.Error("failed to fetch comics", "error", err)
 			return err
 		}
-		s.index.Put(ID, comics.Keywords)
+		s.index.Put(comics)
 		comicsCount++
 	}
 
 	s.log.Debug("rebuilt index", "comics count", comicsCount)
+
+	s.updateSuggestions(ctx, true)
+	s.saveSnapshot(state)
+	return nil
+}
+
+// UpdateIndex applies changes of DB to the index without rebuilding it.
+func (s *Service) UpdateIndex(ctx context.Context, update IndexUpdate) error {
+	if update.Cleaned {
+		s.index.Clear()
+	}
+	state, stateErr := s.dbState(ctx)
+	IDs := slices.Concat(update.Added, update.Reindexed)
+	comics, err := s.db.GetMany(ctx, IDs)
+	if err != nil {
+		s.log.Error("failed to fetch comics", "error", err)
+		return err
+	}
+	found := make(map[int]bool, len(comics))
+	for _, c := range comics {
+		s.index.Put(c)
+		found[c.ID] = true
+	}
+	var removed int
+	for _, ID := range IDs {
+		if !found[ID] {
+			s.index.Remove(ID)
+			removed++
+		}
+	}
+	s.log.Debug("updated index", "added", len(update.Added), "reindexed", len(update.Reindexed),
+		"removed", removed, "cleaned", update.Cleaned)
+	// reindexed comics have new keywords, words may be normalized to them differently
+	s.updateSuggestions(ctx, update.Cleaned || len(update.Reindexed) > 0)
+
+	if stateErr != nil {
+		s.log.Warn("failed to get DB state for index snapshot", "error", stateErr)
+		return nil
+	}
+	s.saveSnapshot(state)
+	return nil
+}
+
+// dbState returns the last comics ID and when comics were last changed in DB.
+func (s *Service) dbState(ctx context.Context) (DBState, error) {
+	lastID, err := s.db.LastID(ctx)
+	if err != nil {
+		return DBState{}, err
+	}
+	updated, err := s.db.Updated(ctx)
+	if err != nil {
+		return DBState{}, err
+	}
+	return DBState{LastID: lastID, Updated: updated}, nil
+}
+
+func (s *Service) saveSnapshot(state DBState) {
+	if s.snapshots == nil {
+		return
+	}
+	if err := s.snapshots.Save(s.index.Snapshot(state)); err != nil {
+		s.log.Warn("failed to save index snapshot", "error", err)
+	}
+}
+
+// RestoreIndex loads the index from snapshot if it is up to date with DB.
+func (s *Service) RestoreIndex(ctx context.Context) error {
+	if s.snapshots == nil {
+		return fmt.Errorf("%w: snapshots are disabled", ErrNotFound)
+	}
+	snapshot, err := s.snapshots.Load()
+	if err != nil {
+		return err
+	}
+	if snapshot.Version != indexVersion {
+		return fmt.Errorf("snapshot version %d is not supported", snapshot.Version)
+	}
+	state, err := s.dbState(ctx)
+	if err != nil {
+		return err
+	}
+	stats, err := s.db.Stats(ctx)
+	if err != nil {
+		return err
+	}
+	if snapshot.LastID != state.LastID || !snapshot.Updated.Equal(state.Updated) ||
+		snapshot.Documents() != stats[FieldWords].Documents {
+		return fmt.Errorf(
+			"snapshot is stale: %d comics up to %d changed at %v, DB has %d up to %d changed at %v",
+			snapshot.Documents(), snapshot.LastID, snapshot.Updated,
+			stats[FieldWords].Documents, state.LastID, state.Updated,
+		)
+	}
+	s.index.Restore(snapshot)
+	s.updateSuggestions(ctx, true)
+
+	s.log.Debug("restored index from snapshot", "comics count", snapshot.Documents())
 	return nil
 }
@@ -0,0 +1,135 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestRestoreIndex(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func(db *fakeDB, snapshots *memorySnapshots)
+		wantErr bool
+	}{
+		{name: "up to date", change: func(*fakeDB, *memorySnapshots) {}},
+		{name: "new comics", change: func(db *fakeDB, _ *memorySnapshots) {
+			db.put(titledComics(3, "Mac"))
+		}, wantErr: true},
+		{name: "removed comics", change: func(db *fakeDB, _ *memorySnapshots) {
+			db.remove(1)
+		}, wantErr: true},
+		{name: "changed comics", change: func(db *fakeDB, _ *memorySnapshots) {
+			db.put(titledComics(2, "Windows 11"))
+		}, wantErr: true},
+		{name: "corrupted snapshot", change: func(_ *fakeDB, snapshots *memorySnapshots) {
+			snapshots.err = errors.New("corrupted snapshot: checksum mismatch")
+		}, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			db := newFakeDB(titledComics(1, "Linux"), titledComics(2, "Windows"))
+			snapshots := &memorySnapshots{}
+			if err := newTestService(t, db, &fakeWords{}, snapshots).BuildIndex(ctx); err != nil {
+				t.Fatalf("BuildIndex failed: %v", err)
+			}
+			test.change(db, snapshots)
+
+			s := newTestService(t, db, &fakeWords{}, snapshots)
+			err := s.RestoreIndex(ctx)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("RestoreIndex() error = %v, want error %v", err, test.wantErr)
+			}
+			if restored := s.index.Stats()[FieldWords].Documents; !test.wantErr && restored != 2 {
+				t.Errorf("restored %d comics, want 2", restored)
+			}
+		})
+	}
+}
+
+func TestUpdateIndexRemovesMissingComics(t *testing.T) {
+	ctx := context.Background()
+	db := newFakeDB(titledComics(1, "Linux"), titledComics(2, "Linux kernel"))
+	s := newTestService(t, db, &fakeWords{}, nil)
+	if err := s.BuildIndex(ctx); err != nil {
+		t.Fatalf("BuildIndex failed: %v", err)
+	}
+
+	db.remove(2)
+	db.put(titledComics(3, "Linux distro"))
+	if err := s.UpdateIndex(ctx, IndexUpdate{Added: []int{3}, Reindexed: []int{2}}); err != nil {
+		t.Fatalf("UpdateIndex failed: %v", err)
+	}
+	var IDs []int
+	for _, p := range s.index.Get(FieldWords, "linux") {
+		IDs = append(IDs, p.ID)
+	}
+	if !slices.Equal(IDs, []int{1, 3}) {
+		t.Errorf("index has comics %v of linux, want [1 3]", IDs)
+	}
+	if documents := s.index.Stats()[FieldWords].Documents; documents != 2 {
+		t.Errorf("index has %d comics, want 2", documents)
+	}
+}
+
+func TestSuggestAfterReindex(t *testing.T) {
+	ctx := context.Background()
+	db := newFakeDB(titledComics(1, "Running"), titledComics(2, "Runner"))
+	words := &fakeWords{}
+	s := newTestService(t, db, words, nil)
+	if err := s.BuildIndex(ctx); err != nil {
+		t.Fatalf("BuildIndex failed: %v", err)
+	}
+	suggestions, err := s.Suggest(ctx, "run", 10)
+	if err != nil || len(suggestions) != 2 {
+		t.Fatalf("Suggest() = %v, %v, want both words", suggestions, err)
+	}
+
+	// another normalizer stems both words to the same keyword
+	words.stems = map[string]string{"running": "run", "runner": "run"}
+	reindexed := Comics{ID: 1, Title: "Running", Keywords: map[Field][]string{FieldWords: {"run"}, FieldTitle: {"run"}}}
+	db.put(reindexed)
+	reindexed.ID, reindexed.Title = 2, "Runner"
+	db.put(reindexed)
+	if err := s.UpdateIndex(ctx, IndexUpdate{Reindexed: []int{1, 2}}); err != nil {
+		t.Fatalf("UpdateIndex failed: %v", err)
+	}
+	suggestions, err = s.Suggest(ctx, "run", 10)
+	if err != nil {
+		t.Fatalf("Suggest failed: %v", err)
+	}
+	want := []Suggestion{{Text: "runner", Keyword: "run", Frequency: 2}}
+	if !slices.Equal(suggestions, want) {
+		t.Errorf("Suggest() = %v, want %v", suggestions, want)
+	}
+}
+
+func TestSearchSuggestion(t *testing.T) {
+	ctx := context.Background()
+	db := newFakeDB(titledComics(1, "Linux kernel"), titledComics(2, "Linux distro"), titledComics(3, "Windows"))
+	s := newTestService(t, db, &fakeWords{}, nil)
+	if err := s.BuildIndex(ctx); err != nil {
+		t.Fatalf("BuildIndex failed: %v", err)
+	}
+	searches := map[string]func(context.Context, SearchRequest) (SearchResult, error){
+		"db": s.Search, "index": s.SearchIndex,
+	}
+	for name, search := range searches {
+		t.Run(name, func(t *testing.T) {
+			result, err := search(ctx, SearchRequest{Phrase: "linuz", Limit: 10})
+			if err != nil || len(result.Comics) != 0 || result.Suggestion != "linux" || result.Corrected {
+				t.Errorf("misspelled: %+v, %v, want only suggestion linux", result, err)
+			}
+			result, err = search(ctx, SearchRequest{Phrase: "linuz", Limit: 10, AutoCorrect: true})
+			if err != nil || result.Total != 2 || result.Suggestion != "linux" || !result.Corrected {
+				t.Errorf("autocorrected: %+v, %v, want 2 comics of linux", result, err)
+			}
+			result, err = search(ctx, SearchRequest{Phrase: "macintosh", Limit: 10})
+			if err != nil || result.Comics == nil || len(result.Comics) != 0 || result.Total != 0 || result.Suggestion != "" {
+				t.Errorf("not found: %+v, %v, want empty result", result, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Highlighter builds short fragments of comics text with matched words wrapped in tags.
+type Highlighter struct {
+	Words   int // snippet length in words
+	PreTag  string
+	PostTag string
+}
+
+func (h Highlighter) validate() error {
+	if h.Words < 1 {
+		return fmt.Errorf("wrong snippet words specified: %d", h.Words)
+	}
+	return nil
+}
+
+// span is a word position in text, the same words as the words service splits.
+type span struct {
+	start, end int
+}
+
+func split(text string) []span {
+	var spans []span
+	start := -1
+	for i, r := range text {
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		switch {
+		case isWord && start < 0:
+			start = i
+		case !isWord && start >= 0:
+			spans = append(spans, span{start: start, end: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		spans = append(spans, span{start: start, end: len(text)})
+	}
+	return spans
+}
+
+// snippet returns a window of text with the most matched words.
+func (h Highlighter) snippet(text string, matched func(word string) bool) string {
+	spans := split(text)
+	if len(spans) == 0 {
+		return ""
+	}
+	hits := make([]bool, len(spans))
+	for i, s := range spans {
+		hits[i] = matched(text[s.start:s.end])
+	}
+
+	// sliding window over words
+	size := min(h.Words, len(spans))
+	var count, best, bestStart int
+	for i := range spans {
+		if hits[i] {
+			count++
+		}
+		if i >= size && hits[i-size] {
+			count--
+		}
+		if i >= size-1 && count > best {
+			best, bestStart = count, i-size+1
+		}
+	}
+
+	var b strings.Builder
+	if bestStart > 0 {
+		b.WriteString("… ")
+	}
+	for i := bestStart; i < bestStart+size; i++ {
+		s := spans[i]
+		if i > bestStart {
+			b.WriteString(collapseSpaces(text[spans[i-1].end:s.start]))
+		}
+		if hits[i] {
+			b.WriteString(h.PreTag)
+			b.WriteString(text[s.start:s.end])
+			b.WriteString(h.PostTag)
+			continue
+		}
+		b.WriteString(text[s.start:s.end])
+	}
+	if bestStart+size < len(spans) {
+		b.WriteString(" …")
+	}
+	return b.String()
+}
+
+func collapseSpaces(separator string) string {
+	fields := strings.Fields(separator)
+	if len(fields) == 0 {
+		return " "
+	}
+	result := strings.Join(fields, " ")
+	if unicode.IsSpace(rune(separator[0])) {
+		result = " " + result
+	}
+	if unicode.IsSpace(rune(separator[len(separator)-1])) {
+		result += " "
+	}
+	return result
+}
+
+// highlight sets snippets of comics, words of comics text are matched
+// by their keywords. Texts of all comics are analyzed at once. If they
+// can not be analyzed, words that look like query keywords are normalized one by one.
+func (s *Service) highlight(ctx context.Context, comics []Comics, keywords map[string]bool) {
+	if len(comics) == 0 {
+		return
+	}
+	texts := make([]string, 0, len(comics))
+	for _, c := range comics {
+		texts = append(texts, c.Text())
+	}
+	analyzed, err := s.words.AnalyzeBatch(ctx, texts)
+	if err == nil && len(analyzed) != len(texts) {
+		err = fmt.Errorf("got tokens of %d texts instead of %d", len(analyzed), len(texts))
+	}
+	if err != nil {
+		s.log.Warn("failed to analyze texts for snippets", "error", err)
+		s.highlightWords(ctx, comics, texts, keywords)
+		return
+	}
+	for i, tokens := range analyzed {
+		hits := make(map[string]bool) // lowercased word -> whether it is a keyword
+		for _, t := range tokens {
+			if !t.Stop && keywords[t.Stem] {
+				hits[strings.ToLower(t.Text)] = true
+			}
+		}
+		comics[i].Snippet = s.highlighter.snippet(texts[i], func(word string) bool {
+			return hits[strings.ToLower(word)]
+		})
+	}
+}
+
+// highlightWords sets snippets of comics normalizing words
+// that look like query keywords one by one.
+func (s *Service) highlightWords(ctx context.Context, comics []Comics, texts []string, keywords map[string]bool) {
+	stems := make(map[string]bool) // lowercased word -> whether it is a keyword
+	matched := func(word string) bool {
+		word = strings.ToLower(word)
+		if found, ok := stems[word]; ok {
+			return found
+		}
+		stems[word] = false
+		if !resembles(word, keywords) {
+			return false
+		}
+		normalized, err := s.words.Norm(ctx, word)
+		if err != nil {
+			s.log.Warn("failed to normalize word for snippet", "word", word, "error", err)
+			return false
+		}
+		for _, stem := range normalized {
+			if keywords[stem] {
+				stems[word] = true
+			}
+		}
+		return stems[word]
+	}
+	for i := range comics {
+		comics[i].Snippet = s.highlighter.snippet(texts[i], matched)
+	}
+}
+
+// resembles reports whether word could be stemmed to one of keywords:
+// a stem shares at least a few first letters with its word.
+func resembles(word string, keywords map[string]bool) bool {
+	for keyword := range keywords {
+		n := min(len(keyword), 3)
+		if len(word) >= n && word[:n] == keyword[:n] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,27 @@
+package core
+
+import (
+	"context"
+	"testing"
+)
+
+func TestHighlightBatch(t *testing.T) {
+	words := &fakeWords{}
+	s := newTestService(t, newFakeDB(), words, nil)
+	comics := []Comics{
+		{ID: 1, Title: "Linux", Alt: "a kernel"},
+		{ID: 2, Title: "Windows", Alt: "no Linux here"},
+		{ID: 3, Title: "Mac"},
+	}
+	s.highlight(context.Background(), comics, map[string]bool{"linux": true})
+
+	if words.calls != 1 || words.batches != 1 {
+		t.Errorf("texts are analyzed in %d calls, want a single batch", words.calls)
+	}
+	want := []string{"<b>Linux</b> a kernel", "Windows no <b>Linux</b> …", "Mac"}
+	for i, c := range comics {
+		if c.Snippet != want[i] {
+			t.Errorf("snippet of comics %d = %q, want %q", c.ID, c.Snippet, want[i])
+		}
+	}
+}
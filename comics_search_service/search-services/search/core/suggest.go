@@ -0,0 +1,155 @@
+package core
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Suggestion is a completion of a word prefix.
+type Suggestion struct {
+	Text      string // the most frequent word of comics text normalized to the keyword
+	Keyword   string
+	Frequency int // number of comics with the keyword
+}
+
+// Words returns distinct words of comics text.
+func (i *Index) Words() []string {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	words := make([]string, 0, len(i.words))
+	for word := range i.words {
+		words = append(words, word)
+	}
+	return words
+}
+
+// UpdateSuggestions rebuilds the dictionary of suggestions
+// from index keywords and words of comics text normalized to them.
+func (i *Index) UpdateSuggestions(stems map[string]string) {
+	i.lock.RLock()
+	f := i.fields[FieldWords]
+	forms := make(map[string]string, len(f.index)) // keyword -> the most frequent word
+	for word, count := range i.words {
+		keyword := stems[word]
+		if _, ok := f.index[keyword]; !ok {
+			continue
+		}
+		form, ok := forms[keyword]
+		if !ok || count > i.words[form] || count == i.words[form] && word < form {
+			forms[keyword] = word
+		}
+	}
+	suggestions := make([]Suggestion, 0, len(f.index))
+	for keyword, postings := range f.index {
+		text, ok := forms[keyword]
+		if !ok {
+			text = keyword
+		}
+		suggestions = append(suggestions, Suggestion{Text: text, Keyword: keyword, Frequency: len(postings)})
+	}
+	i.lock.RUnlock()
+
+	slices.SortFunc(suggestions, func(a, b Suggestion) int {
+		return cmp.Or(strings.Compare(a.Text, b.Text), cmp.Compare(b.Frequency, a.Frequency))
+	})
+	// several keywords may share the most frequent word, the most frequent keyword stays
+	suggestions = slices.CompactFunc(suggestions, func(a, b Suggestion) bool {
+		return a.Text == b.Text
+	})
+
+	i.lock.Lock()
+	i.suggestions = suggestions
+	i.forms = forms
+	i.lock.Unlock()
+}
+
+// Suggest returns up to limit the most frequent suggestions starting with prefix.
+func (i *Index) Suggest(prefix string, limit int) []Suggestion {
+	i.lock.RLock()
+	start, _ := slices.BinarySearchFunc(i.suggestions, prefix, func(s Suggestion, prefix string) int {
+		return strings.Compare(s.Text, prefix)
+	})
+	var found []Suggestion
+	for _, s := range i.suggestions[start:] {
+		if !strings.HasPrefix(s.Text, prefix) {
+			break
+		}
+		found = append(found, s)
+	}
+	i.lock.RUnlock()
+
+	slices.SortStableFunc(found, func(a, b Suggestion) int {
+		return cmp.Compare(b.Frequency, a.Frequency) // desc
+	})
+	if len(found) > limit {
+		found = found[:limit]
+	}
+	return found
+}
+
+func (s *Service) Suggest(ctx context.Context, prefix string, limit int) ([]Suggestion, error) {
+	prefix = strings.ToLower(strings.TrimSpace(prefix))
+	if prefix == "" {
+		return nil, fmt.Errorf("%w: empty prefix", ErrBadArguments)
+	}
+	if limit < 0 {
+		return nil, fmt.Errorf("%w: negative limit", ErrBadArguments)
+	}
+	return s.index.Suggest(prefix, limit), nil
+}
+
+// maxAnalyzeLen is the length of words analyzed at once, less than the words service accepts.
+const maxAnalyzeLen = 10000
+
+// updateSuggestions normalizes new words of comics text and rebuilds suggestions.
+// Normalized words of the index are remembered, so that only new words are sent
+// to the words service, renew normalizes all words again as keywords may change.
+// Suggestions are kept as they were if the words service fails.
+func (s *Service) updateSuggestions(ctx context.Context, renew bool) {
+	s.stemsLock.Lock()
+	defer s.stemsLock.Unlock()
+	words := s.index.Words()
+	stems := make(map[string]string, len(words))
+	var batch strings.Builder
+	analyze := func() error {
+		if batch.Len() == 0 {
+			return nil
+		}
+		tokens, err := s.words.Analyze(ctx, batch.String())
+		if err != nil {
+			return err
+		}
+		for _, t := range tokens {
+			stems[t.Text] = "" // a stop word
+			if !t.Stop {
+				stems[t.Text] = t.Stem
+			}
+		}
+		batch.Reset()
+		return nil
+	}
+	for _, word := range words {
+		if stem, ok := s.stems[word]; ok && !renew {
+			stems[word] = stem
+			continue
+		}
+		if batch.Len()+len(word) >= maxAnalyzeLen {
+			if err := analyze(); err != nil {
+				s.log.Warn("failed to normalize words for suggestions", "error", err)
+				return
+			}
+		}
+		batch.WriteString(word)
+		batch.WriteByte(' ')
+	}
+	if err := analyze(); err != nil {
+		s.log.Warn("failed to normalize words for suggestions", "error", err)
+		return
+	}
+	s.stems = stems
+	s.index.UpdateSuggestions(stems)
+	s.log.Debug("updated suggestions", "words", len(stems), "renewed", renew)
+}
@@ -17,6 +17,7 @@ import (
 	"yadro.com/course/search/adapters/events"
 	searchgrpc "yadro.com/course/search/adapters/grpc"
 	"yadro.com/course/search/adapters/initiator"
+	"yadro.com/course/search/adapters/snapshot"
 	"yadro.com/course/search/adapters/words"
 	"yadro.com/course/search/config"
 	"yadro.com/course/search/core"
@@ -61,8 +62,35 @@ func run(cfg config.Config, log *slog.Logger) error {
 	}
 	defer closers.CloseOrLog(words, log)
 
+	// index snapshots, disabled if no directory is configured
+	var snapshots core.Snapshots
+	if cfg.SnapshotDir != "" {
+		snapshots, err = snapshot.New(log, cfg.SnapshotDir)
+		if err != nil {
+			return err
+		}
+	}
+
 	// service
-	searcher, err := core.NewService(log, storage, words)
+	searcher, err := core.NewService(
+		log, storage, words,
+		core.BM25{
+			K1: cfg.BM25.K1,
+			B:  cfg.BM25.B,
+			Boosts: map[core.Field]float64{
+				core.FieldTitle:      cfg.Boosts.Title,
+				core.FieldAlt:        cfg.Boosts.Alt,
+				core.FieldTranscript: cfg.Boosts.Transcript,
+			},
+		},
+		cfg.MaxExpansions,
+		core.Highlighter{
+			Words:   cfg.Snippet.Words,
+			PreTag:  cfg.Snippet.PreTag,
+			PostTag: cfg.Snippet.PostTag,
+		},
+		snapshots,
+	)
 	if err != nil {
 		return fmt.Errorf("failed create Update service: %v", err)
 	}
@@ -0,0 +1,5 @@
+ALTER TABLE comics
+    DROP COLUMN IF EXISTS title,
+    DROP COLUMN IF EXISTS safe_title,
+    DROP COLUMN IF EXISTS transcript,
+    DROP COLUMN IF EXISTS alt;
\ No newline at end of file
@@ -0,0 +1,4 @@
+ALTER TABLE comics
+    ADD COLUMN title_words TEXT[] NOT NULL DEFAULT '{}',
+    ADD COLUMN alt_words TEXT[] NOT NULL DEFAULT '{}',
+    ADD COLUMN transcript_words TEXT[] NOT NULL DEFAULT '{}';
\ No newline at end of file
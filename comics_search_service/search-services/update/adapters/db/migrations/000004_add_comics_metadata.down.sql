@@ -0,0 +1,3 @@
+ALTER TABLE comics
+    DROP COLUMN IF EXISTS published,
+    DROP COLUMN IF EXISTS permalink;
\ No newline at end of file
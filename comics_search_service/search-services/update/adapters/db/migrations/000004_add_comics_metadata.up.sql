@@ -0,0 +1,3 @@
+ALTER TABLE comics
+    ADD COLUMN published DATE,
+    ADD COLUMN permalink TEXT NOT NULL DEFAULT '';
\ No newline at end of file
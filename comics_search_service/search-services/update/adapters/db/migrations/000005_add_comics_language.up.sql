@@ -0,0 +1,2 @@
+ALTER TABLE comics
+    ADD COLUMN language TEXT NOT NULL DEFAULT '';
\ No newline at end of file
@@ -0,0 +1,7 @@
+CREATE TABLE failed_comics (
+    id int PRIMARY KEY,
+    stage TEXT NOT NULL,
+    error TEXT NOT NULL,
+    attempts int NOT NULL,
+    updated TIMESTAMPTZ NOT NULL
+);
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE comics
+    DROP COLUMN IF EXISTS raw,
+    DROP COLUMN IF EXISTS normalizer;
\ No newline at end of file
@@ -0,0 +1,10 @@
+ALTER TABLE comics
+    ADD COLUMN raw JSONB,
+    ADD COLUMN normalizer TEXT NOT NULL DEFAULT '';
+UPDATE comics SET raw = jsonb_build_object(
+    'num', id, 'img', url, 'title', title, 'safe_title', safe_title,
+    'transcript', transcript, 'alt', alt,
+    'year', coalesce(extract(year FROM published)::int::text, ''),
+    'month', coalesce(extract(month FROM published)::int::text, ''),
+    'day', coalesce(extract(day FROM published)::int::text, '')
+);
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE comics
+    DROP COLUMN IF EXISTS updated;
\ No newline at end of file
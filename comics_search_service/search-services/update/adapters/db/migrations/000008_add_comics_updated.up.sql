@@ -0,0 +1,2 @@
+ALTER TABLE comics
+    ADD COLUMN updated TIMESTAMPTZ NOT NULL DEFAULT now();
\ No newline at end of file
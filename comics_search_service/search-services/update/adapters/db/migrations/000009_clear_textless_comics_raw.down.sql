@@ -0,0 +1,8 @@
+UPDATE comics SET raw = jsonb_build_object(
+    'num', id, 'img', url, 'title', title, 'safe_title', safe_title,
+    'transcript', transcript, 'alt', alt,
+    'year', coalesce(extract(year FROM published)::int::text, ''),
+    'month', coalesce(extract(month FROM published)::int::text, ''),
+    'day', coalesce(extract(day FROM published)::int::text, '')
+)
+WHERE raw IS NULL AND title = '' AND safe_title = '' AND transcript = '' AND alt = '';
\ No newline at end of file
@@ -0,0 +1,2 @@
+UPDATE comics SET raw = NULL
+WHERE title = '' AND safe_title = '' AND transcript = '' AND alt = '';
\ No newline at end of file
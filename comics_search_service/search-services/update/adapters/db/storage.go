@@ -37,13 +37,50 @@ func (db *DB) Close() error {
 func (db *DB) Add(ctx context.Context, comics core.Comics) error {
 	_, err := db.conn.ExecContext(
 		ctx,
-		"INSERT INTO comics (id, url, words) VALUES($1, $2, $3)",
+		`INSERT INTO comics (id, url, words, title_words, alt_words, transcript_words,
+			title, safe_title, transcript, alt, published, permalink, language, raw, normalizer)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		ON CONFLICT (id) DO UPDATE SET
+			url = EXCLUDED.url, words = EXCLUDED.words, title_words = EXCLUDED.title_words,
+			alt_words = EXCLUDED.alt_words, transcript_words = EXCLUDED.transcript_words,
+			title = EXCLUDED.title, safe_title = EXCLUDED.safe_title,
+			transcript = EXCLUDED.transcript, alt = EXCLUDED.alt,
+			published = EXCLUDED.published, permalink = EXCLUDED.permalink,
+			language = EXCLUDED.language, raw = EXCLUDED.raw, normalizer = EXCLUDED.normalizer,
+			updated = now()`,
 		comics.ID, comics.URL, comics.Words,
+		comics.TitleWords, comics.AltWords, comics.TranscriptWords,
+		comics.Title, comics.SafeTitle, comics.Transcript, comics.Alt,
+		sql.NullTime{Time: comics.Published, Valid: !comics.Published.IsZero()}, comics.Permalink,
+		comics.Language, comics.Raw, comics.Normalizer,
 	)
 
 	return err
 }
 
+func (db *DB) Raw(ctx context.Context, ID int) ([]byte, error) {
+	var raw []byte
+	err := db.conn.GetContext(ctx, &raw, "SELECT raw FROM comics WHERE id = $1", ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, core.ErrNotFound
+		}
+		return nil, err
+	}
+	return raw, nil
+}
+
+func (db *DB) Outdated(ctx context.Context, version string) ([]int, error) {
+	var IDs []int
+	err := db.conn.SelectContext(
+		ctx, &IDs,
+		"SELECT id FROM comics WHERE normalizer <> $1 ORDER BY id", version)
+	if err != nil {
+		return nil, err
+	}
+	return IDs, nil
+}
+
 func (db *DB) Stats(ctx context.Context) (core.DBStats, error) {
 	var stats core.DBStats
 	err := db.conn.GetContext(
@@ -85,8 +122,37 @@ func (db *DB) IDs(ctx context.Context) ([]int, error) {
 	return IDs, nil
 }
 
+func (db *DB) AddFailure(ctx context.Context, failure core.Failure) error {
+	_, err := db.conn.ExecContext(
+		ctx,
+		`INSERT INTO failed_comics (id, stage, error, attempts, updated)
+		VALUES($1, $2, $3, $4, now())
+		ON CONFLICT (id) DO UPDATE SET
+			stage = EXCLUDED.stage, error = EXCLUDED.error,
+			attempts = failed_comics.attempts + EXCLUDED.attempts, updated = EXCLUDED.updated`,
+		failure.ID, failure.Stage, failure.Error, failure.Attempts,
+	)
+	return err
+}
+
+func (db *DB) Failures(ctx context.Context) ([]core.Failure, error) {
+	var failures []core.Failure
+	err := db.conn.SelectContext(
+		ctx, &failures,
+		"SELECT id, stage, error, attempts, updated FROM failed_comics ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	return failures, nil
+}
+
+func (db *DB) DeleteFailures(ctx context.Context, IDs []int) error {
+	_, err := db.conn.ExecContext(ctx, "DELETE FROM failed_comics WHERE id = ANY($1)", IDs)
+	return err
+}
+
 func (db *DB) Drop(ctx context.Context) error {
 
-	_, err := db.conn.ExecContext(ctx, "TRUNCATE comics")
+	_, err := db.conn.ExecContext(ctx, "TRUNCATE comics, failed_comics")
 	return err
 }
@@ -1,16 +1,34 @@
 package events
 
 import (
+	"encoding/json"
 	"log/slog"
+	"strconv"
+	"sync"
+	"time"
 
 	"github.com/nats-io/nats.go"
 )
 
 const updateTopic = "xkcd.db.updated"
 
+// event describes a change of DB. Events of one publisher share the epoch
+// and are numbered sequentially, so that subscribers can notice missed ones.
+type event struct {
+	Epoch   string `json:"epoch"`
+	Seq     uint64 `json:"seq"`
+	Added   []int  `json:"added,omitempty"`
+	Cleaned bool   `json:"cleaned,omitempty"`
+	// stored comics normalized again
+	Reindexed []int `json:"reindexed,omitempty"`
+}
+
 type Broker struct {
 	connection *nats.Conn
 	log        *slog.Logger
+	epoch      string
+	seq        uint64
+	lock       sync.Mutex
 }
 
 func New(address string, log *slog.Logger) (*Broker, error) {
@@ -19,17 +37,37 @@ func New(address string, log *slog.Logger) (*Broker, error) {
 		return nil, err
 	}
 	log.Debug("connected to broker", "address", address)
-	return &Broker{connection: nc, log: log}, nil
+	return &Broker{
+		connection: nc,
+		log:        log,
+		epoch:      strconv.FormatInt(time.Now().UnixNano(), 36),
+	}, nil
 }
 
 func (b *Broker) Close() {
 	b.connection.Close()
 }
 
-func (b *Broker) NotifyDbUpdated() error {
-	return b.connection.Publish(updateTopic, []byte("XKCD DB has been updated"))
+func (b *Broker) NotifyDbUpdated(IDs []int) error {
+	return b.publish(event{Added: IDs})
+}
+
+func (b *Broker) NotifyDbReindexed(IDs []int) error {
+	return b.publish(event{Reindexed: IDs})
 }
 
 func (b *Broker) NotifyDbCleaned() error {
-	return b.connection.Publish(updateTopic, []byte("XKCD DB has been cleaned"))
+	return b.publish(event{Cleaned: true})
+}
+
+func (b *Broker) publish(e event) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.seq++
+	e.Epoch, e.Seq = b.epoch, b.seq
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return b.connection.Publish(updateTopic, data)
 }
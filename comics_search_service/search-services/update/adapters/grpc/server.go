@@ -3,10 +3,13 @@ package grpc
 import (
 	"context"
 	"errors"
+	"time"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 	updatepb "yadro.com/course/proto/update"
 	"yadro.com/course/update/core"
 )
@@ -25,27 +28,136 @@ func (s *Server) Ping(_ context.Context, _ *emptypb.Empty) (*emptypb.Empty, erro
 }
 
 func (s *Server) Status(ctx context.Context, _ *emptypb.Empty) (*updatepb.StatusReply, error) {
-	st := s.service.Status(ctx)
+	return statusReply(s.service.Status(ctx))
+}
 
-	switch st {
+func (s *Server) WatchStatus(_ *emptypb.Empty, stream updatepb.Update_WatchStatusServer) error {
+	for {
+		info, changed := s.service.WatchStatus()
+		reply, err := statusReply(info)
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(reply); err != nil {
+			return err
+		}
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-changed:
+		}
+	}
+}
+
+func statusReply(info core.StatusInfo) (*updatepb.StatusReply, error) {
+	reply := &updatepb.StatusReply{}
+	switch info.Status {
 	case core.StatusIdle:
-		return &updatepb.StatusReply{Status: updatepb.Status_STATUS_IDLE}, nil
+		reply.Status = updatepb.Status_STATUS_IDLE
 	case core.StatusRunning:
-		return &updatepb.StatusReply{Status: updatepb.Status_STATUS_RUNNING}, nil
+		reply.Status = updatepb.Status_STATUS_RUNNING
+	default:
+		return nil, status.Error(codes.Internal, "unknown status from service")
+	}
+	if !info.LastRun.IsZero() {
+		reply.LastRun = timestamppb.New(info.LastRun)
+	}
+	if !info.NextRun.IsZero() {
+		reply.NextRun = timestamppb.New(info.NextRun)
+	}
+	if !info.Progress.Started.IsZero() {
+		reply.Progress = progressReply(info.Progress)
+	}
+	return reply, nil
+}
+
+func progressReply(p core.Progress) *updatepb.Progress {
+	reply := &updatepb.Progress{
+		Started:    timestamppb.New(p.Started),
+		Planned:    int64(p.Planned),
+		Fetched:    int64(p.Fetched),
+		Normalized: int64(p.Normalized),
+		Stored:     int64(p.Stored),
+		Failed:     int64(p.Failed),
+		Canceled:   p.Canceled,
+	}
+	if !p.Finished.IsZero() {
+		reply.Finished = timestamppb.New(p.Finished)
 	}
-	return nil, status.Error(codes.Internal, "unknown status from service")
+	if eta := p.ETA(time.Now()); eta > 0 {
+		reply.Eta = durationpb.New(eta)
+	}
+	return reply
 }
 
 func (s *Server) Update(ctx context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
 	if err := s.service.Update(ctx); err != nil {
-		if errors.Is(err, core.ErrAlreadyExists) {
+		switch {
+		case errors.Is(err, core.ErrAlreadyExists):
+			return nil, status.Error(codes.AlreadyExists, "update already runs")
+		case errors.Is(err, core.ErrCanceled):
+			return nil, status.Error(codes.Aborted, err.Error())
+		}
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (s *Server) Cancel(ctx context.Context, _ *emptypb.Empty) (*updatepb.Progress, error) {
+	progress, err := s.service.Cancel(ctx)
+	if err != nil {
+		if errors.Is(err, core.ErrNotFound) {
+			return nil, status.Error(codes.NotFound, "no update runs")
+		}
+		return nil, err
+	}
+	return progressReply(progress), nil
+}
+
+func (s *Server) RetryFailed(ctx context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
+	if err := s.service.RetryFailed(ctx); err != nil {
+		switch {
+		case errors.Is(err, core.ErrAlreadyExists):
+			return nil, status.Error(codes.AlreadyExists, "update already runs")
+		case errors.Is(err, core.ErrCanceled):
+			return nil, status.Error(codes.Aborted, err.Error())
+		}
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (s *Server) Reindex(ctx context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
+	if err := s.service.Reindex(ctx); err != nil {
+		switch {
+		case errors.Is(err, core.ErrAlreadyExists):
 			return nil, status.Error(codes.AlreadyExists, "update already runs")
+		case errors.Is(err, core.ErrCanceled):
+			return nil, status.Error(codes.Aborted, err.Error())
 		}
 		return nil, err
 	}
 	return nil, nil
 }
 
+func (s *Server) Failures(ctx context.Context, _ *emptypb.Empty) (*updatepb.FailuresReply, error) {
+	failures, err := s.service.Failures(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reply := &updatepb.FailuresReply{Failures: make([]*updatepb.Failure, 0, len(failures))}
+	for _, f := range failures {
+		reply.Failures = append(reply.Failures, &updatepb.Failure{
+			Id:       int64(f.ID),
+			Stage:    string(f.Stage),
+			Error:    f.Error,
+			Attempts: int64(f.Attempts),
+			Updated:  timestamppb.New(f.Updated),
+		})
+	}
+	return reply, nil
+}
+
 func (s *Server) Stats(ctx context.Context, _ *emptypb.Empty) (*updatepb.StatsReply, error) {
 	stats, err := s.service.Stats(ctx)
 	if err != nil {
@@ -61,6 +173,9 @@ func (s *Server) Stats(ctx context.Context, _ *emptypb.Empty) (*updatepb.StatsRe
 
 func (s *Server) Drop(ctx context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
 	if err := s.service.Drop(ctx); err != nil {
+		if errors.Is(err, core.ErrAlreadyExists) {
+			return nil, status.Error(codes.AlreadyExists, "update runs")
+		}
 		return nil, err
 	}
 	return nil, nil
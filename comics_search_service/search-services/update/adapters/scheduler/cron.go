@@ -0,0 +1,115 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cron is a parsed five-field expression: minute hour day-of-month month day-of-week.
+// Fields accept *, numbers, ranges a-b, lists a,b and steps */n or a-b/n.
+type cron struct {
+	minutes, hours, days, months, weekdays []bool
+	anyDay, anyWeekday                     bool
+}
+
+func parseCron(spec string) (*cron, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron %q: need 5 fields, got %d", spec, len(fields))
+	}
+	var c cron
+	var err error
+	for _, f := range []struct {
+		text     string
+		min, max int
+		set      *[]bool
+	}{
+		{fields[0], 0, 59, &c.minutes},
+		{fields[1], 0, 23, &c.hours},
+		{fields[2], 1, 31, &c.days},
+		{fields[3], 1, 12, &c.months},
+		{fields[4], 0, 7, &c.weekdays}, // both 0 and 7 are Sunday
+	} {
+		if *f.set, err = parseCronField(f.text, f.min, f.max); err != nil {
+			return nil, fmt.Errorf("cron %q: %w", spec, err)
+		}
+	}
+	c.weekdays[0] = c.weekdays[0] || c.weekdays[7]
+	c.anyDay = fields[2] == "*"
+	c.anyWeekday = fields[4] == "*"
+	return &c, nil
+}
+
+func parseCronField(text string, min, max int) ([]bool, error) {
+	set := make([]bool, max+1)
+	for _, part := range strings.Split(text, ",") {
+		rng, stepText, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepText); err != nil || step < 1 {
+				return nil, fmt.Errorf("bad step %q", part)
+			}
+		}
+		first, last := min, max
+		if rng != "*" {
+			from, to, isRange := strings.Cut(rng, "-")
+			var err error
+			if first, err = strconv.Atoi(from); err != nil {
+				return nil, fmt.Errorf("bad value %q", part)
+			}
+			last = first
+			if isRange {
+				if last, err = strconv.Atoi(to); err != nil {
+					return nil, fmt.Errorf("bad value %q", part)
+				}
+			} else if hasStep {
+				last = max
+			}
+		}
+		if first < min || last > max || first > last {
+			return nil, fmt.Errorf("value %q is out of range %d-%d", part, min, max)
+		}
+		for i := first; i <= last; i += step {
+			set[i] = true
+		}
+	}
+	return set, nil
+}
+
+// next returns the first matching minute after t, zero time if nothing matches within years.
+func (c *cron) next(t time.Time) time.Time {
+	// truncated in local time, zones may be offset by a fraction of an hour
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute()+1, 0, 0, t.Location())
+	for limit := t.AddDate(5, 0, 0); t.Before(limit); {
+		switch {
+		case !c.months[t.Month()]:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !c.matchDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case !c.hours[t.Hour()]:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case !c.minutes[t.Minute()]:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// matchDay follows cron: if both day fields are restricted, either may match.
+func (c *cron) matchDay(t time.Time) bool {
+	day, weekday := c.days[t.Day()], c.weekdays[t.Weekday()]
+	switch {
+	case c.anyDay && c.anyWeekday:
+		return true
+	case c.anyDay:
+		return weekday
+	case c.anyWeekday:
+		return day
+	}
+	return day || weekday
+}
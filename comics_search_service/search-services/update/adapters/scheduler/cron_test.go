@@ -0,0 +1,68 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCronInvalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"1-b * * * *",
+		"1,,2 * * * *",
+	} {
+		t.Run(spec, func(t *testing.T) {
+			if _, err := parseCron(spec); err == nil {
+				t.Errorf("parseCron(%q) succeeded", spec)
+			}
+		})
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	utc := time.UTC
+	india := time.FixedZone("IST", 5*3600+30*60)
+	tests := []struct {
+		spec string
+		from time.Time
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2024, 3, 1, 10, 10, 30, 0, utc), time.Date(2024, 3, 1, 10, 11, 0, 0, utc)},
+		{"*/15 * * * *", time.Date(2024, 3, 1, 10, 10, 0, 0, utc), time.Date(2024, 3, 1, 10, 15, 0, 0, utc)},
+		{"*/15 * * * *", time.Date(2024, 3, 1, 10, 45, 0, 0, utc), time.Date(2024, 3, 1, 11, 0, 0, 0, utc)},
+		{"0 */6 * * *", time.Date(2024, 3, 1, 13, 0, 0, 0, utc), time.Date(2024, 3, 1, 18, 0, 0, 0, utc)},
+		{"30 9-17 * * *", time.Date(2024, 3, 1, 17, 45, 0, 0, utc), time.Date(2024, 3, 2, 9, 30, 0, 0, utc)},
+		{"0 8-18/5 * * *", time.Date(2024, 3, 1, 9, 0, 0, 0, utc), time.Date(2024, 3, 1, 13, 0, 0, 0, utc)},
+		{"0 9,12,18 * * *", time.Date(2024, 3, 1, 12, 0, 0, 0, utc), time.Date(2024, 3, 1, 18, 0, 0, 0, utc)},
+		{"0 0 1 * *", time.Date(2024, 1, 31, 12, 0, 0, 0, utc), time.Date(2024, 2, 1, 0, 0, 0, 0, utc)},
+		{"0 0 29 2 *", time.Date(2023, 3, 1, 0, 0, 0, 0, utc), time.Date(2024, 2, 29, 0, 0, 0, 0, utc)},
+		{"0 0 * * 1-5", time.Date(2024, 3, 1, 12, 0, 0, 0, utc), time.Date(2024, 3, 4, 0, 0, 0, 0, utc)}, // Friday
+		{"0 0 * * 7", time.Date(2024, 3, 1, 12, 0, 0, 0, utc), time.Date(2024, 3, 3, 0, 0, 0, 0, utc)},
+		{"0 0 13 * 5", time.Date(2024, 3, 2, 0, 0, 0, 0, utc), time.Date(2024, 3, 8, 0, 0, 0, 0, utc)},
+		{"0 12 * * *", time.Date(2024, 3, 1, 10, 10, 0, 0, india), time.Date(2024, 3, 1, 12, 0, 0, 0, india)},
+		{"*/20 * * * *", time.Date(2024, 3, 1, 10, 10, 0, 0, india), time.Date(2024, 3, 1, 10, 20, 0, 0, india)},
+		{"0 0 30 2 *", time.Date(2024, 3, 1, 0, 0, 0, 0, utc), time.Time{}},
+	}
+	for _, test := range tests {
+		t.Run(test.spec+" "+test.from.String(), func(t *testing.T) {
+			c, err := parseCron(test.spec)
+			if err != nil {
+				t.Fatalf("parseCron failed: %v", err)
+			}
+			if got := c.next(test.from); !got.Equal(test.want) {
+				t.Errorf("next() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
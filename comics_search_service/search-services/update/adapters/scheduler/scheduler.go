@@ -0,0 +1,66 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"yadro.com/course/update/core"
+)
+
+// Scheduler checks XKCD for new comics periodically or by cron expression.
+type Scheduler struct {
+	log     *slog.Logger
+	updater core.Updater
+	period  time.Duration
+	cron    *cron
+}
+
+// New creates a scheduler, cron expression takes precedence over period if given.
+func New(log *slog.Logger, updater core.Updater, period time.Duration, cronSpec string) (*Scheduler, error) {
+	s := &Scheduler{log: log, updater: updater, period: period}
+	if cronSpec != "" {
+		var err error
+		if s.cron, err = parseCron(cronSpec); err != nil {
+			return nil, err
+		}
+		return s, nil
+	}
+	if period <= 0 {
+		return nil, fmt.Errorf("wrong check period specified: %s", period)
+	}
+	return s, nil
+}
+
+func (s *Scheduler) next(now time.Time) time.Time {
+	if s.cron != nil {
+		return s.cron.next(now)
+	}
+	return now.Add(s.period)
+}
+
+func (s *Scheduler) Run(ctx context.Context) {
+	go func() {
+		for {
+			next := s.next(time.Now())
+			if next.IsZero() {
+				s.log.Error("cron expression never matches, scheduler stopped")
+				return
+			}
+			s.updater.Schedule(next)
+			timer := time.NewTimer(time.Until(next))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				s.log.Debug("quit scheduler")
+				return
+			case <-timer.C:
+				s.log.Info("run scheduled update check")
+				if err := s.updater.CheckUpdate(ctx); err != nil {
+					s.log.Error("scheduled update failed", "error", err)
+				}
+			}
+		}
+	}()
+}
@@ -2,6 +2,7 @@ package words
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 
 	"google.golang.org/grpc"
@@ -34,15 +35,103 @@ func (c *Client) Close() error {
 	return c.conn.Close()
 }
 
-func (c *Client) Norm(ctx context.Context, phrase string) ([]string, error) {
-	reply, err := c.client.Norm(ctx, &wordspb.WordsRequest{Phrase: phrase})
+// NormBatch normalizes phrases in a few round-trips. Keywords are kept
+// with repeats, so that their frequencies can be counted. Phrases longer than
+// the words service accepts are split between words, and keywords of the parts are joined.
+func (c *Client) NormBatch(ctx context.Context, phrases []string) ([]core.Keywords, error) {
+	var requests []*wordspb.WordsRequest
+	var owners []int // phrase of each request
+	var total int
+	for i, phrase := range phrases {
+		for _, part := range wordspb.SplitPhrase(phrase, wordspb.MaxPhraseLen) {
+			requests = append(requests, &wordspb.WordsRequest{Phrase: part, Repeats: true})
+			owners = append(owners, i)
+			total += len(part)
+		}
+	}
+
+	// more phrases than fit a single message are streamed
+	normalize := c.normBatch
+	if total > wordspb.MaxBatchLen {
+		normalize = c.normStream
+	}
+	replies, err := normalize(ctx, requests)
 	if err != nil {
-		if status.Code(err) == codes.ResourceExhausted {
+		switch status.Code(err) {
+		case codes.ResourceExhausted:
 			return nil, core.ErrBadArguments
+		case codes.Unavailable, codes.DeadlineExceeded:
+			return nil, fmt.Errorf("%w: %v", core.ErrUnavailable, err)
 		}
 		return nil, err
 	}
-	return reply.GetWords(), nil
+	if len(replies) != len(requests) {
+		return nil, fmt.Errorf("got %d replies for %d phrases", len(replies), len(requests))
+	}
+
+	result := make([]core.Keywords, len(phrases))
+	for j, reply := range replies {
+		i := owners[j]
+		if j == 0 || owners[j-1] != i {
+			result[i].Language = reply.GetLanguage()
+			result[i].Version = reply.GetVersion()
+		}
+		result[i].Words = append(result[i].Words, reply.GetWords()...)
+	}
+	return result, nil
+}
+
+func (c *Client) Version(ctx context.Context) (string, error) {
+	reply, err := c.client.Version(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	return reply.GetVersion(), nil
+}
+
+func (c *Client) normBatch(
+	ctx context.Context, requests []*wordspb.WordsRequest,
+) ([]*wordspb.WordsReply, error) {
+	reply, err := c.client.NormBatch(ctx, &wordspb.WordsBatchRequest{Requests: requests})
+	if err != nil {
+		return nil, err
+	}
+	return reply.GetReplies(), nil
+}
+
+func (c *Client) normStream(
+	ctx context.Context, requests []*wordspb.WordsRequest,
+) ([]*wordspb.WordsReply, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := c.client.NormStream(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sent := make(chan error, 1)
+	go func() {
+		for _, req := range requests {
+			if err := stream.Send(req); err != nil {
+				sent <- err // the reason is returned by Recv
+				return
+			}
+		}
+		sent <- stream.CloseSend()
+	}()
+
+	replies := make([]*wordspb.WordsReply, 0, len(requests))
+	for range requests {
+		reply, err := stream.Recv()
+		if err != nil {
+			return nil, err
+		}
+		replies = append(replies, reply)
+	}
+	if err := <-sent; err != nil {
+		return nil, err
+	}
+	return replies, nil
 }
 
 func (c *Client) Ping(ctx context.Context) error {
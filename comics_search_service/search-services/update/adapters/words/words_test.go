@@ -0,0 +1,100 @@
+package words
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+	wordspb "yadro.com/course/proto/words"
+)
+
+var errNotSupported = errors.New("not supported by fake")
+
+// fakeWords replies with words of each phrase as keywords.
+type fakeWords struct {
+	t        *testing.T
+	requests int
+}
+
+func (w *fakeWords) NormBatch(
+	_ context.Context, in *wordspb.WordsBatchRequest, _ ...grpc.CallOption,
+) (*wordspb.WordsBatchReply, error) {
+	reply := &wordspb.WordsBatchReply{}
+	for _, req := range in.GetRequests() {
+		w.requests++
+		if len(req.GetPhrase()) > wordspb.MaxPhraseLen {
+			w.t.Errorf("phrase of %d bytes is sent", len(req.GetPhrase()))
+		}
+		if !req.GetRepeats() {
+			w.t.Error("repeated keywords are not requested")
+		}
+		reply.Replies = append(reply.Replies, &wordspb.WordsReply{
+			Words:    strings.Fields(req.GetPhrase()),
+			Language: "en",
+			Version:  "2",
+		})
+	}
+	return reply, nil
+}
+
+func (*fakeWords) Ping(context.Context, *emptypb.Empty, ...grpc.CallOption) (*emptypb.Empty, error) {
+	return nil, errNotSupported
+}
+
+func (*fakeWords) Norm(context.Context, *wordspb.WordsRequest, ...grpc.CallOption) (*wordspb.WordsReply, error) {
+	return nil, errNotSupported
+}
+
+func (*fakeWords) NormStream(
+	context.Context, ...grpc.CallOption,
+) (grpc.BidiStreamingClient[wordspb.WordsRequest, wordspb.WordsReply], error) {
+	return nil, errNotSupported
+}
+
+func (*fakeWords) Version(context.Context, *emptypb.Empty, ...grpc.CallOption) (*wordspb.VersionReply, error) {
+	return nil, errNotSupported
+}
+
+func (*fakeWords) Expand(context.Context, *wordspb.WordsRequest, ...grpc.CallOption) (*wordspb.ExpandReply, error) {
+	return nil, errNotSupported
+}
+
+func (*fakeWords) Analyze(context.Context, *wordspb.WordsRequest, ...grpc.CallOption) (*wordspb.AnalyzeReply, error) {
+	return nil, errNotSupported
+}
+
+func (*fakeWords) AnalyzeBatch(context.Context, *wordspb.WordsBatchRequest, ...grpc.CallOption) (*wordspb.AnalyzeBatchReply, error) {
+	return nil, errNotSupported
+}
+
+func TestNormBatchSplitsLongPhrases(t *testing.T) {
+	words := &fakeWords{t: t}
+	c := &Client{log: slog.New(slog.NewTextHandler(io.Discard, nil)), client: words}
+
+	long := strings.Repeat("linux ", wordspb.MaxPhraseLen/3) // twice as long as allowed
+	keywords, err := c.NormBatch(context.Background(), []string{"kernel", long, "cat"})
+	if err != nil {
+		t.Fatalf("NormBatch failed: %v", err)
+	}
+	if words.requests < 4 {
+		t.Errorf("%d requests are sent, the long phrase must be split", words.requests)
+	}
+	if len(keywords) != 3 {
+		t.Fatalf("got keywords of %d phrases, want 3", len(keywords))
+	}
+	if !slices.Equal(keywords[0].Words, []string{"kernel"}) || !slices.Equal(keywords[2].Words, []string{"cat"}) {
+		t.Errorf("keywords of short phrases are %v and %v", keywords[0].Words, keywords[2].Words)
+	}
+	if got := len(keywords[1].Words); got != wordspb.MaxPhraseLen/3 {
+		t.Errorf("long phrase has %d keywords, want %d", got, wordspb.MaxPhraseLen/3)
+	}
+	if keywords[1].Language != "en" || keywords[1].Version != "2" {
+		t.Errorf("long phrase is stemmed for %q by version %q", keywords[1].Language, keywords[1].Version)
+	}
+}
@@ -4,8 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -51,12 +54,26 @@ func (c Client) get(ctx context.Context, url string) (core.XKCDInfo, error) {
 	}
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return core.XKCDInfo{}, fmt.Errorf("failed to request comics: %v", err)
+		// network errors and timeouts
+		return core.XKCDInfo{}, fmt.Errorf("%w: failed to request comics: %v", core.ErrUnavailable, err)
 	}
 	defer closers.CloseOrLog(resp.Body, c.log)
-	if resp.StatusCode == http.StatusNotFound {
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
 		return core.XKCDInfo{}, core.ErrNotFound
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError:
+		return core.XKCDInfo{}, fmt.Errorf("%w: xkcd replied %s", core.ErrUnavailable, resp.Status)
+	case resp.StatusCode != http.StatusOK:
+		return core.XKCDInfo{}, fmt.Errorf("xkcd replied %s", resp.Status)
 	}
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return core.XKCDInfo{}, fmt.Errorf("%w: failed to read comics: %v", core.ErrUnavailable, err)
+	}
+	return c.Decode(raw)
+}
+
+func (c Client) Decode(raw []byte) (core.XKCDInfo, error) {
 	info := struct {
 		ID         int    `json:"num"`
 		URL        string `json:"img"`
@@ -64,17 +81,53 @@ func (c Client) get(ctx context.Context, url string) (core.XKCDInfo, error) {
 		SafeTitle  string `json:"safe_title"`
 		Transcript string `json:"transcript"`
 		Alt        string `json:"alt"`
+		Year       string `json:"year"`
+		Month      string `json:"month"`
+		Day        string `json:"day"`
 	}{}
-	if err = json.NewDecoder(resp.Body).Decode(&info); err != nil {
+	if err := json.Unmarshal(raw, &info); err != nil {
 		return core.XKCDInfo{}, fmt.Errorf("failed to decode comics: %v", err)
 	}
+	published, err := publicationDate(info.Year, info.Month, info.Day)
+	if err != nil {
+		c.log.Warn("bad publication date", "id", info.ID, "error", err)
+	}
+	// the same safe title would count title words twice
+	texts := []string{info.Title, info.SafeTitle, info.Transcript, info.Alt}
+	if info.SafeTitle == info.Title {
+		texts = slices.Delete(texts, 1, 2)
+	}
 
 	return core.XKCDInfo{
-		ID:  info.ID,
-		URL: info.URL,
-		Description: strings.Join([]string{
-			info.Title, info.SafeTitle, info.Transcript, info.Alt},
-			" ",
-		),
+		ID:          info.ID,
+		URL:         info.URL,
+		Title:       info.Title,
+		SafeTitle:   info.SafeTitle,
+		Transcript:  info.Transcript,
+		Alt:         info.Alt,
+		Published:   published,
+		Permalink:   fmt.Sprintf("%s/%d/", c.url, info.ID),
+		Description: strings.Join(texts, " "),
+		Raw:         raw,
 	}, nil
 }
+
+// publicationDate parses the date xkcd gives as separate numbers without leading zeros.
+func publicationDate(year, month, day string) (time.Time, error) {
+	if year == "" {
+		return time.Time{}, nil
+	}
+	y, err := strconv.Atoi(year)
+	if err != nil {
+		return time.Time{}, err
+	}
+	m, err := strconv.Atoi(month)
+	if err != nil {
+		return time.Time{}, err
+	}
+	d, err := strconv.Atoi(day)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Date(y, time.Month(m), d, 0, 0, 0, 0, time.UTC), nil
+}
@@ -0,0 +1,45 @@
+package xkcd
+
+import (
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// termFrequency counts the word in the text as search ranks it.
+func termFrequency(text, word string) int {
+	var tf int
+	for _, w := range strings.Fields(strings.ToLower(text)) {
+		if w == word {
+			tf++
+		}
+	}
+	return tf
+}
+
+func TestDecodeDescriptionRanking(t *testing.T) {
+	c := Client{log: slog.New(slog.NewTextHandler(io.Discard, nil)), url: "https://xkcd.com"}
+	titled, err := c.Decode([]byte(`{"num":1,"title":"Linux","safe_title":"Linux","alt":"kernel"}`))
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	mentioned, err := c.Decode([]byte(`{"num":2,"title":"Kernel","safe_title":"Kernel","alt":"linux and linux"}`))
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	renamed, err := c.Decode([]byte(`{"num":3,"title":"Linux!","safe_title":"Linux","alt":"kernel"}`))
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+
+	if tf := termFrequency(titled.Description, "linux"); tf != 1 {
+		t.Errorf("title word is counted %d times in %q, want once", tf, titled.Description)
+	}
+	if termFrequency(mentioned.Description, "linux") <= termFrequency(titled.Description, "linux") {
+		t.Errorf("comics mentioning the word twice does not outrank comics titled by it once")
+	}
+	if !strings.Contains(renamed.Description, "Linux! Linux") {
+		t.Errorf("different safe title is dropped from %q", renamed.Description)
+	}
+}
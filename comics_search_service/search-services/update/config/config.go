@@ -12,6 +12,12 @@ type XKCD struct {
 	Concurrency int           `yaml:"concurrency" env:"XKCD_CONCURRENCY" env-default:"1"`
 	Timeout     time.Duration `yaml:"timeout" env:"XKCD_TIMEOUT" env-default:"10s"`
 	CheckPeriod time.Duration `yaml:"check_period" env:"XKCD_CHECK_PERIOD" env-default:"1h"`
+	// CheckCron overrides CheckPeriod, like "0 */6 * * *"
+	CheckCron string `yaml:"check_cron" env:"XKCD_CHECK_CRON"`
+	// a comics is fetched or normalized at most RetryAttempts times
+	RetryAttempts int           `yaml:"retry_attempts" env:"XKCD_RETRY_ATTEMPTS" env-default:"3"`
+	RetryDelay    time.Duration `yaml:"retry_delay" env:"XKCD_RETRY_DELAY" env-default:"1s"`
+	RetryMaxDelay time.Duration `yaml:"retry_max_delay" env:"XKCD_RETRY_MAX_DELAY" env-default:"30s"`
 }
 
 type Config struct {
@@ -20,6 +26,7 @@ type Config struct {
 	XKCD          XKCD   `yaml:"xkcd"`
 	DBAddress     string `yaml:"db_address" env:"DB_ADDRESS" env-default:"localhost:82"`
 	WordsAddress  string `yaml:"words_address" env:"WORDS_ADDRESS" env-default:"localhost:81"`
+	WordsBatch    int    `yaml:"words_batch" env:"WORDS_BATCH" env-default:"50"`
 	BrokerAddress string `yaml:"broker_address" env:"BROKER_ADDRESS" env-default:"localhost:4222"`
 }
 
@@ -5,3 +5,5 @@ import "errors"
 var ErrBadArguments = errors.New("arguments are not acceptable")
 var ErrAlreadyExists = errors.New("resource or task already exists")
 var ErrNotFound = errors.New("resource is not found")
+var ErrCanceled = errors.New("task is canceled")
+var ErrUnavailable = errors.New("resource is temporarily unavailable")
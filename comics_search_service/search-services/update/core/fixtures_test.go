@@ -0,0 +1,199 @@
+package core
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestService(t *testing.T, db DB, xkcd XKCD, words Words, notifier Notifier) *Service {
+	t.Helper()
+	s, err := NewService(
+		slog.New(slog.NewTextHandler(io.Discard, nil)), db, xkcd, words, 1, 1,
+		Backoff{Attempts: 1, Delay: time.Millisecond, MaxDelay: time.Millisecond}, notifier,
+	)
+	if err != nil {
+		t.Fatalf("failed to create service: %v", err)
+	}
+	return s
+}
+
+// fakeDB keeps comics and failures in memory.
+type fakeDB struct {
+	mu       sync.Mutex
+	comics   map[int]Comics
+	failures []Failure
+}
+
+// newFakeDB returns DB with comics of the IDs stored without XKCD JSON.
+func newFakeDB(IDs ...int) *fakeDB {
+	db := &fakeDB{comics: make(map[int]Comics)}
+	for _, ID := range IDs {
+		db.comics[ID] = Comics{ID: ID}
+	}
+	return db
+}
+
+func (db *fakeDB) Add(_ context.Context, c Comics) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.comics[c.ID] = c
+	return nil
+}
+
+func (db *fakeDB) Raw(_ context.Context, ID int) ([]byte, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.comics[ID].Raw, nil
+}
+
+func (db *fakeDB) Outdated(_ context.Context, version string) ([]int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	var IDs []int
+	for _, c := range db.comics {
+		if c.Normalizer != version {
+			IDs = append(IDs, c.ID)
+		}
+	}
+	slices.Sort(IDs)
+	return IDs, nil
+}
+
+func (db *fakeDB) Stats(context.Context) (DBStats, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	unique := make(map[string]bool)
+	stats := DBStats{ComicsFetched: len(db.comics)}
+	for _, c := range db.comics {
+		stats.WordsTotal += len(c.Words)
+		for _, w := range c.Words {
+			unique[w] = true
+		}
+	}
+	stats.WordsUnique = len(unique)
+	return stats, nil
+}
+
+func (db *fakeDB) Drop(context.Context) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	clear(db.comics)
+	db.failures = nil
+	return nil
+}
+
+func (db *fakeDB) IDs(context.Context) ([]int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return slices.Sorted(maps.Keys(db.comics)), nil
+}
+
+func (db *fakeDB) AddFailure(_ context.Context, failure Failure) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for i, f := range db.failures {
+		if f.ID == failure.ID {
+			failure.Attempts += f.Attempts
+			db.failures[i] = failure
+			return nil
+		}
+	}
+	db.failures = append(db.failures, failure)
+	return nil
+}
+
+func (db *fakeDB) Failures(context.Context) ([]Failure, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return slices.Clone(db.failures), nil
+}
+
+func (db *fakeDB) DeleteFailures(_ context.Context, IDs []int) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.failures = slices.DeleteFunc(db.failures, func(f Failure) bool {
+		return slices.Contains(IDs, f.ID)
+	})
+	return nil
+}
+
+// fakeXKCD has comics up to lastID titled "fetched", raw JSON is the title.
+type fakeXKCD struct {
+	mu     sync.Mutex
+	lastID int
+	got    []int
+	hold   bool // comics are not got until the request is canceled
+}
+
+func (x *fakeXKCD) Get(ctx context.Context, ID int) (XKCDInfo, error) {
+	x.mu.Lock()
+	x.got = append(x.got, ID)
+	hold := x.hold
+	x.mu.Unlock()
+	if hold {
+		<-ctx.Done()
+		return XKCDInfo{}, ctx.Err()
+	}
+	return XKCDInfo{ID: ID, Title: "fetched", Raw: []byte("fetched")}, nil
+}
+
+func (x *fakeXKCD) LastID(context.Context) (int, error) {
+	return x.lastID, nil
+}
+
+func (x *fakeXKCD) Decode(raw []byte) (XKCDInfo, error) {
+	return XKCDInfo{Title: string(raw)}, nil
+}
+
+// fakeWords takes words of phrases as their keywords.
+type fakeWords struct{}
+
+const fakeVersion = "1"
+
+func (fakeWords) NormBatch(_ context.Context, phrases []string) ([]Keywords, error) {
+	keywords := make([]Keywords, 0, len(phrases))
+	for _, phrase := range phrases {
+		keywords = append(keywords, Keywords{Words: strings.Fields(phrase), Language: "en", Version: fakeVersion})
+	}
+	return keywords, nil
+}
+
+func (fakeWords) Version(context.Context) (string, error) {
+	return fakeVersion, nil
+}
+
+// fakeNotifier records IDs of notifications.
+type fakeNotifier struct {
+	mu        sync.Mutex
+	updated   []int
+	reindexed []int
+	cleaned   int
+}
+
+func (n *fakeNotifier) NotifyDbUpdated(IDs []int) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.updated = append(n.updated, IDs...)
+	return nil
+}
+
+func (n *fakeNotifier) NotifyDbReindexed(IDs []int) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.reindexed = append(n.reindexed, IDs...)
+	return nil
+}
+
+func (n *fakeNotifier) NotifyDbCleaned() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.cleaned++
+	return nil
+}
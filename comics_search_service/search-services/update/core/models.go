@@ -1,5 +1,7 @@
 package core
 
+import "time"
+
 type ServiceStatus string
 
 const (
@@ -7,6 +9,30 @@ const (
 	StatusIdle    ServiceStatus = "idle"
 )
 
+type StatusInfo struct {
+	Status   ServiceStatus
+	LastRun  time.Time // last scheduled check, zero if none yet
+	NextRun  time.Time // zero if updates are not scheduled
+	Progress Progress  // zero if there were no updates
+}
+
+type Stage string
+
+const (
+	StageFetch     Stage = "fetch"
+	StageNormalize Stage = "normalize"
+	StageStore     Stage = "store"
+)
+
+// Failure is a comics failed at some stage of the last update it took part in.
+type Failure struct {
+	ID       int
+	Stage    Stage
+	Error    string
+	Attempts int       // made in all updates since the comics first failed
+	Updated  time.Time // when the comics failed last time
+}
+
 type DBStats struct {
 	WordsTotal    int
 	WordsUnique   int
@@ -19,13 +45,40 @@ type ServiceStats struct {
 }
 
 type Comics struct {
-	ID    int
-	URL   string
-	Words []string
+	ID              int
+	URL             string
+	Words           []string // keywords of the whole comics
+	TitleWords      []string
+	AltWords        []string
+	TranscriptWords []string
+	Title           string
+	SafeTitle       string
+	Transcript      string
+	Alt             string
+	Published       time.Time // zero if unknown
+	Permalink       string
+	Language        string // detected language of the comics text
+	Raw             []byte // JSON as XKCD gives it, nil if the comics is not from XKCD
+	Normalizer      string // version of normalization the keywords are got by
+}
+
+// Keywords are normalized words of a text in order, repeated ones included,
+// and the language they were stemmed for.
+type Keywords struct {
+	Words    []string
+	Language string
+	Version  string // of normalization
 }
 
 type XKCDInfo struct {
 	ID          int
 	URL         string
+	Title       string
+	SafeTitle   string
+	Transcript  string
+	Alt         string
+	Published   time.Time
+	Permalink   string
 	Description string
+	Raw         []byte
 }
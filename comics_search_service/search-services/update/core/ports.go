@@ -2,32 +2,63 @@ package core
 
 import (
 	"context"
+	"time"
 )
 
 type Updater interface {
+	// Update waits for the update, which is not stopped if ctx is done, only by Cancel.
 	Update(context.Context) error
+	// Cancel stops the running update and returns its progress once it is stopped.
+	Cancel(context.Context) (Progress, error)
 	Stats(context.Context) (ServiceStats, error)
-	Status(context.Context) ServiceStatus
+	Status(context.Context) StatusInfo
+	// WatchStatus returns the status and a channel closed when it changes.
+	WatchStatus() (StatusInfo, <-chan struct{})
+	// Schedule records when the next automatic update check runs.
+	Schedule(next time.Time)
+	// CheckUpdate runs an update if XKCD has comics missing in DB, failed comics aside.
+	CheckUpdate(context.Context) error
+	// Reindex normalizes again comics stored with an outdated normalization version.
+	Reindex(context.Context) error
+	Failures(context.Context) ([]Failure, error)
+	// RetryFailed runs an update of the failed comics only.
+	RetryFailed(context.Context) error
 	Drop(context.Context) error
 }
 
 type DB interface {
+	// Add stores comics replacing the stored one.
 	Add(context.Context, Comics) error
+	// Raw returns JSON of the comics as XKCD gave it, nil if it is not kept.
+	Raw(ctx context.Context, ID int) ([]byte, error)
+	// Outdated returns IDs of comics normalized not by the version.
+	Outdated(ctx context.Context, version string) ([]int, error)
 	Stats(context.Context) (DBStats, error)
 	Drop(context.Context) error
 	IDs(context.Context) ([]int, error)
+	// AddFailure records a failure adding up attempts of the comics.
+	AddFailure(context.Context, Failure) error
+	Failures(context.Context) ([]Failure, error)
+	DeleteFailures(ctx context.Context, IDs []int) error
 }
 
+// XKCD and Words return errors wrapping ErrUnavailable if a request is worth retrying.
 type XKCD interface {
 	Get(context.Context, int) (XKCDInfo, error)
 	LastID(context.Context) (int, error)
+	// Decode parses comics JSON as XKCD gives it.
+	Decode(raw []byte) (XKCDInfo, error)
 }
 
 type Words interface {
-	Norm(ctx context.Context, phrase string) ([]string, error)
+	// NormBatch returns keywords of phrases in the same order.
+	NormBatch(ctx context.Context, phrases []string) ([]Keywords, error)
+	// Version of normalization.
+	Version(context.Context) (string, error)
 }
 
 type Notifier interface {
-	NotifyDbUpdated() error
+	NotifyDbUpdated(IDs []int) error
+	NotifyDbReindexed(IDs []int) error
 	NotifyDbCleaned() error
 }
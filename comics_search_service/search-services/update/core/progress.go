@@ -0,0 +1,54 @@
+package core
+
+import (
+	"sync"
+	"time"
+)
+
+// Progress of the running or the last finished update.
+type Progress struct {
+	Started    time.Time
+	Finished   time.Time // zero while running
+	Planned    int       // comics to process
+	Fetched    int
+	Normalized int
+	Stored     int
+	Failed     int  // at any stage
+	Canceled   bool // comics left are neither processed nor failed
+}
+
+// ETA estimates time left by the rate comics have been processed so far, zero if unknown.
+func (p Progress) ETA(now time.Time) time.Duration {
+	done := p.Stored + p.Failed
+	if !p.Finished.IsZero() || done == 0 || done >= p.Planned {
+		return 0
+	}
+	perComics := now.Sub(p.Started) / time.Duration(done)
+	return perComics * time.Duration(p.Planned-done)
+}
+
+// tracker keeps the service status and wakes up watchers on its changes.
+type tracker struct {
+	info    StatusInfo
+	changed chan struct{}
+	lock    sync.Mutex
+}
+
+func newTracker() *tracker {
+	return &tracker{info: StatusInfo{Status: StatusIdle}, changed: make(chan struct{})}
+}
+
+func (t *tracker) update(change func(info *StatusInfo)) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	change(&t.info)
+	close(t.changed)
+	t.changed = make(chan struct{})
+}
+
+// get returns the status and a channel closed on its next change.
+func (t *tracker) get() (StatusInfo, <-chan struct{}) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.info, t.changed
+}
@@ -0,0 +1,36 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+)
+
+// Backoff retries operations failed with ErrUnavailable,
+// delays double from Delay up to MaxDelay and are jittered.
+type Backoff struct {
+	Attempts int
+	Delay    time.Duration
+	MaxDelay time.Duration
+}
+
+// retry runs op until it succeeds, fails permanently or runs out of attempts,
+// and returns the number of attempts made.
+func (b Backoff) retry(ctx context.Context, op func() error) (int, error) {
+	delay := b.Delay
+	for attempt := 1; ; attempt++ {
+		err := op()
+		if err == nil || attempt >= b.Attempts || !errors.Is(err, ErrUnavailable) {
+			return attempt, err
+		}
+		// half of the delay is random, so concurrent fetchers do not retry at once
+		wait := delay/2 + rand.N(delay/2+1)
+		select {
+		case <-ctx.Done():
+			return attempt, err
+		case <-time.After(wait):
+		}
+		delay = min(2*delay, b.MaxDelay)
+	}
+}
@@ -2,10 +2,11 @@ package core
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 	"sync"
-	"sync/atomic"
 	"time"
 )
 
@@ -16,45 +17,248 @@ type Service struct {
 	words       Words
 	notifier    Notifier
 	concurrency int
-	inProgress  atomic.Bool
-	lock        sync.Mutex
+	batchSize   int
+	backoff     Backoff
+	status      *tracker
+	lock        sync.Mutex // held while an update runs
+	job         *job
+	jobLock     sync.Mutex
+}
+
+// job is the running update.
+type job struct {
+	cancel   context.CancelFunc
+	finished chan struct{}
 }
 
 func NewService(
-	log *slog.Logger, db DB, xkcd XKCD, words Words, concurrency int, notifier Notifier,
+	log *slog.Logger, db DB, xkcd XKCD, words Words, concurrency, batchSize int, backoff Backoff,
+	notifier Notifier,
 ) (*Service, error) {
 	if concurrency < 1 {
 		return nil, fmt.Errorf("wrong concurrency specified: %d", concurrency)
 	}
+	if batchSize < 1 {
+		return nil, fmt.Errorf("wrong batch size specified: %d", batchSize)
+	}
+	if backoff.Attempts < 1 {
+		return nil, fmt.Errorf("wrong retry attempts specified: %d", backoff.Attempts)
+	}
+	if backoff.Delay <= 0 || backoff.MaxDelay < backoff.Delay {
+		return nil, fmt.Errorf("wrong retry delays specified: %v, %v", backoff.Delay, backoff.MaxDelay)
+	}
 	return &Service{
 		log:         log,
 		db:          db,
 		xkcd:        xkcd,
 		words:       words,
 		concurrency: concurrency,
+		batchSize:   batchSize,
+		backoff:     backoff,
 		notifier:    notifier,
+		status:      newTracker(),
 	}, nil
 }
 
-func (s *Service) Update(ctx context.Context) (err error) {
+// task is a kind of update: which comics it processes,
+// where it gets them from and how it notifies about processed ones.
+type task struct {
+	name   string
+	plan   func(context.Context) ([]int, error)
+	get    func(context.Context, int) (XKCDInfo, error)
+	notify func(IDs []int) error
+}
+
+func (s *Service) Update(ctx context.Context) error {
+	return s.start(ctx, task{
+		name:   "update",
+		plan:   s.missingIDs,
+		get:    s.xkcd.Get,
+		notify: s.notifier.NotifyDbUpdated,
+	})
+}
+
+// RetryFailed processes again only the comics recorded as failed.
+func (s *Service) RetryFailed(ctx context.Context) error {
+	return s.start(ctx, task{
+		name:   "retry",
+		plan:   s.failedIDs,
+		get:    s.xkcd.Get,
+		notify: s.notifier.NotifyDbUpdated,
+	})
+}
+
+// Reindex normalizes again stored comics without fetching them from XKCD.
+func (s *Service) Reindex(ctx context.Context) error {
+	return s.start(ctx, task{
+		name:   "reindex",
+		plan:   s.outdatedIDs,
+		get:    s.stored,
+		notify: s.notifier.NotifyDbReindexed,
+	})
+}
+
+// start runs process in background and waits for it while ctx is not done,
+// only one process runs at a time.
+func (s *Service) start(ctx context.Context, t task) error {
 	if ok := s.lock.TryLock(); !ok {
 		s.log.Error("service already runs update")
 		return ErrAlreadyExists
 	}
-	defer s.lock.Unlock()
 
-	s.inProgress.Store(true)
-	defer s.inProgress.Store(false)
+	jobCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	j := &job{cancel: cancel, finished: make(chan struct{})}
+	s.jobLock.Lock()
+	s.job = j
+	s.jobLock.Unlock()
+
+	result := make(chan error, 1)
+	go func() {
+		err := s.process(jobCtx, t)
+		cancel()
+		s.jobLock.Lock()
+		s.job = nil
+		s.jobLock.Unlock()
+		// the lock is released first, so that an update may start once Cancel returns
+		s.lock.Unlock()
+		close(j.finished)
+		result <- err
+	}()
+
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		s.log.Info(t.name+" continues in background", "reason", ctx.Err())
+		return ctx.Err()
+	}
+}
+
+func (s *Service) Cancel(ctx context.Context) (Progress, error) {
+	s.jobLock.Lock()
+	j := s.job
+	s.jobLock.Unlock()
+	if j == nil {
+		return Progress{}, ErrNotFound
+	}
 
-	s.log.Info("update started")
+	s.log.Info("canceling update")
+	j.cancel()
+	select {
+	case <-j.finished:
+	case <-ctx.Done():
+		return Progress{}, ctx.Err()
+	}
+	info, _ := s.status.get()
+	return info.Progress, nil
+}
+
+// process gets, normalizes and stores comics planned by the task.
+func (s *Service) process(ctx context.Context, t task) (err error) {
+	s.status.update(func(info *StatusInfo) {
+		info.Status = StatusRunning
+		info.Progress = Progress{Started: time.Now()}
+	})
+	defer s.status.update(func(info *StatusInfo) {
+		info.Status = StatusIdle
+		info.Progress.Finished = time.Now()
+	})
+
+	s.log.Info(t.name + " started")
 	defer func(start time.Time) {
-		s.log.Info("update finished", "duration", time.Since(start), "error", err)
+		s.log.Info(t.name+" finished", "duration", time.Since(start), "error", err)
 	}(time.Now())
 
-	// get existing IDs in DB
+	IDs, err := t.plan(ctx)
+	if err != nil {
+		return err
+	}
+	s.status.update(func(info *StatusInfo) { info.Progress.Planned = len(IDs) })
+
+	generator := generateIDs(ctx, IDs)
+	fetchers := s.getComics(ctx, generator, t.get)
+
+	var added []int
+	save := func(batch []XKCDInfo) {
+		var comics []Comics
+		attempts, err := s.backoff.retry(ctx, func() (err error) {
+			comics, err = s.normalize(ctx, batch)
+			return err
+		})
+		if err != nil {
+			for _, info := range batch {
+				s.fail(ctx, Failure{ID: info.ID, Stage: StageNormalize, Error: err.Error(), Attempts: attempts})
+			}
+			return
+		}
+		s.status.update(func(info *StatusInfo) { info.Progress.Normalized += len(comics) })
+		for _, c := range comics {
+			if err := s.db.Add(ctx, c); err != nil {
+				s.fail(ctx, Failure{ID: c.ID, Stage: StageStore, Error: err.Error(), Attempts: 1})
+				continue
+			}
+			added = append(added, c.ID)
+			s.status.update(func(info *StatusInfo) { info.Progress.Stored++ })
+		}
+	}
+	batch := make([]XKCDInfo, 0, s.batchSize)
+	for info := range fetchers {
+		batch = append(batch, info)
+		if len(batch) == s.batchSize {
+			save(batch)
+			batch = batch[:0]
+		}
+	}
+	if len(batch) > 0 {
+		save(batch)
+	}
+	s.log.Debug("stored comics", "count", len(added))
+
+	// stored comics are kept even if the process is canceled
+	canceled := ctx.Err() != nil
+	ctx = context.WithoutCancel(ctx)
+
+	if len(added) > 0 {
+		// stored comics are not failed anymore
+		if err := s.db.DeleteFailures(ctx, added); err != nil {
+			s.log.Warn("could not delete resolved failures", "error", err)
+		}
+		// notify all subscribers about stored comics, even if some have failed
+		if err := t.notify(added); err != nil {
+			s.log.Warn("could not send db update notification", "error", err)
+		}
+	}
+
+	if canceled {
+		s.status.update(func(info *StatusInfo) { info.Progress.Canceled = true })
+		return fmt.Errorf("%w: stored %d of %d comics", ErrCanceled, len(added), len(IDs))
+	}
+	if failed := len(IDs) - len(added); failed > 0 {
+		return fmt.Errorf("failed to process %d of %d comics, see failures", failed, len(IDs))
+	}
+
+	return nil
+}
+
+// fail records a comics failed at some stage, so it can be retried later.
+func (s *Service) fail(ctx context.Context, failure Failure) {
+	if ctx.Err() != nil {
+		// interrupted by cancel, not failed
+		return
+	}
+	s.log.Error("failed to process comics",
+		"id", failure.ID, "stage", failure.Stage, "attempts", failure.Attempts, "error", failure.Error)
+	s.status.update(func(info *StatusInfo) { info.Progress.Failed++ })
+	if err := s.db.AddFailure(ctx, failure); err != nil {
+		s.log.Warn("could not record failure", "id", failure.ID, "error", err)
+	}
+}
+
+// missingIDs returns IDs of XKCD comics missing in DB.
+func (s *Service) missingIDs(ctx context.Context) ([]int, error) {
 	IDs, err := s.db.IDs(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to get existing IDs in DB: %v", err)
+		return nil, fmt.Errorf("failed to get existing IDs in DB: %v", err)
 	}
 	s.log.Debug("existing comics in DB", "count", len(IDs))
 	exists := make(map[int]bool, len(IDs))
@@ -62,71 +266,123 @@ func (s *Service) Update(ctx context.Context) (err error) {
 		exists[id] = true
 	}
 
-	// get last comics ID
 	lastID, err := s.xkcd.LastID(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to get last ID in XKCD: %v", err)
+		return nil, fmt.Errorf("failed to get last ID in XKCD: %v", err)
 	}
 	s.log.Debug("last comics ID in XKCD", "id", lastID)
 
-	generator := generateIDs(ctx, 1, lastID, exists)
-	fetchers := s.getComics(ctx, generator)
-
-	var errorsFound bool
-	var added int
-	for info := range fetchers {
-		words, err := s.words.Norm(ctx, info.Description)
-		if err != nil {
-			errorsFound = true
-			s.log.Error("failed to normalize", "id", info.ID, "error", err)
-			continue
+	var missing []int
+	for id := 1; id <= lastID; id++ {
+		if !exists[id] {
+			missing = append(missing, id)
 		}
-		err = s.db.Add(ctx, Comics{
-			ID:    info.ID,
-			URL:   info.URL,
-			Words: words,
-		})
-		if err != nil {
-			errorsFound = true
-			s.log.Error("failed to save comics", "id", info.ID, "error", err)
-			continue
-		}
-		added++
 	}
-	s.log.Debug("added new comics", "count", added)
+	return missing, nil
+}
 
-	if errorsFound {
-		return fmt.Errorf("failed to fetch/store some comics")
+// outdatedIDs returns IDs of comics stored with an outdated normalization version.
+func (s *Service) outdatedIDs(ctx context.Context) ([]int, error) {
+	version, err := s.words.Version(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get normalization version: %v", err)
+	}
+	IDs, err := s.db.Outdated(ctx, version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get outdated IDs in DB: %v", err)
 	}
+	s.log.Debug("outdated comics in DB", "version", version, "count", len(IDs))
+	return IDs, nil
+}
 
-	// notify about updates all subscribers
-	if err = s.notifier.NotifyDbUpdated(); err != nil {
-		s.log.Warn("could not send db update notification", "error", err)
+// stored gets comics from its JSON kept in DB,
+// comics stored without it are fetched from XKCD again.
+func (s *Service) stored(ctx context.Context, ID int) (XKCDInfo, error) {
+	raw, err := s.db.Raw(ctx, ID)
+	if err != nil {
+		return XKCDInfo{}, err
+	}
+	if len(raw) == 0 {
+		s.log.Debug("no XKCD JSON of comics in DB, fetching", "id", ID)
+		return s.xkcd.Get(ctx, ID)
 	}
+	return s.xkcd.Decode(raw)
+}
 
-	return nil
+func (s *Service) failedIDs(ctx context.Context) ([]int, error) {
+	failures, err := s.db.Failures(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get failures from DB: %v", err)
+	}
+	IDs := make([]int, 0, len(failures))
+	for _, f := range failures {
+		IDs = append(IDs, f.ID)
+	}
+	return IDs, nil
+}
+
+// normalize gets keywords of the whole comics and of its fields separately,
+// all texts of a batch are normalized at once.
+func (s *Service) normalize(ctx context.Context, batch []XKCDInfo) ([]Comics, error) {
+	const fields = 4 // texts of each comics
+	phrases := make([]string, 0, fields*len(batch))
+	for _, info := range batch {
+		title := info.Title
+		if info.SafeTitle != info.Title {
+			title += " " + info.SafeTitle
+		}
+		phrases = append(phrases, info.Description, title, info.Alt, info.Transcript)
+	}
+	keywords, err := s.words.NormBatch(ctx, phrases)
+	if err != nil {
+		return nil, err
+	}
+	if len(keywords) != len(phrases) {
+		return nil, fmt.Errorf("got keywords of %d texts instead of %d", len(keywords), len(phrases))
+	}
+
+	result := make([]Comics, 0, len(batch))
+	for i, info := range batch {
+		k := keywords[fields*i : fields*(i+1)]
+		result = append(result, Comics{
+			ID:              info.ID,
+			URL:             info.URL,
+			Words:           k[0].Words,
+			TitleWords:      k[1].Words,
+			AltWords:        k[2].Words,
+			TranscriptWords: k[3].Words,
+			Title:           info.Title,
+			SafeTitle:       info.SafeTitle,
+			Transcript:      info.Transcript,
+			Alt:             info.Alt,
+			Published:       info.Published,
+			Permalink:       info.Permalink,
+			Language:        k[0].Language, // of the whole comics
+			Raw:             info.Raw,
+			Normalizer:      k[0].Version,
+		})
+	}
+	return result, nil
 }
 
-func generateIDs(ctx context.Context, first, last int, exists map[int]bool) <-chan int {
+func generateIDs(ctx context.Context, IDs []int) <-chan int {
 	ch := make(chan int)
 	go func() {
 		defer close(ch)
-		for i := first; i <= last; i++ {
-			if exists[i] {
-				continue
-			}
+		for _, id := range IDs {
 			select {
 			case <-ctx.Done():
 				return
-			default:
-				ch <- i
+			case ch <- id:
 			}
 		}
 	}()
 	return ch
 }
 
-func (s *Service) getComics(ctx context.Context, in <-chan int) <-chan XKCDInfo {
+func (s *Service) getComics(
+	ctx context.Context, in <-chan int, get func(context.Context, int) (XKCDInfo, error),
+) <-chan XKCDInfo {
 	out := make(chan XKCDInfo)
 	var wg sync.WaitGroup
 	wg.Add(s.concurrency)
@@ -139,15 +395,21 @@ func (s *Service) getComics(ctx context.Context, in <-chan int) <-chan XKCDInfo
 			for id := range in {
 				if id == 404 {
 					// special case
-					out <- XKCDInfo{ID: id, Description: "404 Not found"}
+					s.status.update(func(info *StatusInfo) { info.Progress.Fetched++ })
+					out <- XKCDInfo{ID: id, Title: "404 Not found", Description: "404 Not found"}
 					continue
 				}
-				info, err := s.xkcd.Get(ctx, id)
+				var info XKCDInfo
+				attempts, err := s.backoff.retry(ctx, func() (err error) {
+					info, err = get(ctx, id)
+					return err
+				})
 				if err != nil {
-					s.log.Error("failed to get comics", "id", id, "error", err)
+					s.fail(ctx, Failure{ID: id, Stage: StageFetch, Error: err.Error(), Attempts: attempts})
 					continue
 				}
 				s.log.Debug("fetched", "id", id)
+				s.status.update(func(info *StatusInfo) { info.Progress.Fetched++ })
 				out <- info
 			}
 		}()
@@ -177,14 +439,69 @@ func (s *Service) Stats(ctx context.Context) (ServiceStats, error) {
 	}, nil
 }
 
-func (s *Service) Status(ctx context.Context) ServiceStatus {
-	if s.inProgress.Load() {
-		return StatusRunning
+func (s *Service) Status(ctx context.Context) StatusInfo {
+	info, _ := s.status.get()
+	return info
+}
+
+func (s *Service) WatchStatus() (StatusInfo, <-chan struct{}) {
+	return s.status.get()
+}
+
+func (s *Service) Schedule(next time.Time) {
+	s.status.update(func(info *StatusInfo) { info.NextRun = next })
+}
+
+// CheckUpdate runs an update if XKCD has comics missing in DB which have
+// not failed before, the check is skipped while another update runs.
+func (s *Service) CheckUpdate(ctx context.Context) error {
+	s.status.update(func(info *StatusInfo) { info.LastRun = time.Now() })
+
+	if s.Status(ctx).Status == StatusRunning {
+		s.log.Info("update already runs, scheduled check skipped")
+		return nil
 	}
-	return StatusIdle
+	missing, err := s.missingIDs(ctx)
+	if err != nil {
+		return err
+	}
+	// failed comics are left to RetryFailed, they would start an update on every check
+	failed, err := s.failedIDs(ctx)
+	if err != nil {
+		return err
+	}
+	known := make(map[int]bool, len(failed))
+	for _, ID := range failed {
+		known[ID] = true
+	}
+	missing = slices.DeleteFunc(missing, func(ID int) bool { return known[ID] })
+	if len(missing) == 0 {
+		s.log.Debug("no new comics in XKCD")
+		return nil
+	}
+	s.log.Info("new comics in XKCD", "count", len(missing))
+	if err := s.Update(ctx); err != nil && !errors.Is(err, ErrAlreadyExists) {
+		return err
+	}
+	return nil
+}
+
+func (s *Service) Failures(ctx context.Context) ([]Failure, error) {
+	failures, err := s.db.Failures(ctx)
+	if err != nil {
+		s.log.Error("failed to get failures", "error", err)
+		return nil, err
+	}
+	return failures, nil
 }
 
+// Drop removes all comics, it fails with ErrAlreadyExists while an update runs.
 func (s *Service) Drop(ctx context.Context) error {
+	if ok := s.lock.TryLock(); !ok {
+		s.log.Error("could not drop db while update runs")
+		return ErrAlreadyExists
+	}
+	defer s.lock.Unlock()
 	err := s.db.Drop(ctx)
 	if err != nil {
 		s.log.Error("failed to drop db entries", "error", err)
@@ -0,0 +1,83 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCheckUpdateSkipsFailedComics(t *testing.T) {
+	db := newFakeDB(1, 2, 4)
+	db.failures = []Failure{{ID: 3, Stage: StageFetch}, {ID: 5, Stage: StageFetch}}
+	xkcd := &fakeXKCD{lastID: 5}
+	s := newTestService(t, db, xkcd, &fakeWords{}, &fakeNotifier{})
+
+	if err := s.CheckUpdate(context.Background()); err != nil {
+		t.Fatalf("CheckUpdate failed: %v", err)
+	}
+	if len(xkcd.got) > 0 {
+		t.Errorf("update of failed comics %v is started", xkcd.got)
+	}
+	if status := s.Status(context.Background()); status.Status == StatusRunning || status.LastRun.IsZero() {
+		t.Errorf("status %+v after the check", status)
+	}
+}
+
+func TestStoredWithoutRaw(t *testing.T) {
+	db := newFakeDB(1, 2)
+	db.comics[1] = Comics{ID: 1, Raw: []byte("kept")}
+	xkcd := &fakeXKCD{}
+	s := newTestService(t, db, xkcd, &fakeWords{}, &fakeNotifier{})
+
+	info, err := s.stored(context.Background(), 1)
+	if err != nil || info.Title != "kept" {
+		t.Errorf("stored(1) = %+v, %v, want comics decoded from DB", info, err)
+	}
+	info, err = s.stored(context.Background(), 2)
+	if err != nil || info.Title != "fetched" {
+		t.Errorf("stored(2) = %+v, %v, want comics fetched from XKCD", info, err)
+	}
+	if len(xkcd.got) != 1 || xkcd.got[0] != 2 {
+		t.Errorf("fetched comics %v, want only 2", xkcd.got)
+	}
+}
+
+func TestUpdateAfterCancel(t *testing.T) {
+	ctx := context.Background()
+	db := newFakeDB()
+	xkcd := &fakeXKCD{lastID: 3, hold: true}
+	s := newTestService(t, db, xkcd, &fakeWords{}, &fakeNotifier{})
+
+	canceled := make(chan error, 1)
+	go func() { canceled <- s.Update(ctx) }()
+	deadline := time.Now().Add(5 * time.Second)
+	for s.Status(ctx).Status != StatusRunning {
+		if time.Now().After(deadline) {
+			t.Fatal("update is not started")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := s.Drop(ctx); !errors.Is(err, ErrAlreadyExists) {
+		t.Errorf("Drop() while update runs error = %v, want ErrAlreadyExists", err)
+	}
+	if _, err := s.Cancel(ctx); err != nil {
+		t.Fatalf("Cancel failed: %v", err)
+	}
+	xkcd.mu.Lock()
+	xkcd.hold = false
+	xkcd.mu.Unlock()
+	if err := s.Update(ctx); err != nil {
+		t.Errorf("Update() right after Cancel error = %v", err)
+	}
+	if err := <-canceled; !errors.Is(err, ErrCanceled) {
+		t.Errorf("canceled Update() error = %v, want ErrCanceled", err)
+	}
+	if IDs, _ := db.IDs(ctx); len(IDs) != 3 {
+		t.Errorf("stored comics %v, want 3", IDs)
+	}
+	if err := s.Drop(ctx); err != nil {
+		t.Errorf("Drop() after update error = %v", err)
+	}
+}
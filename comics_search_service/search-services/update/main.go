@@ -16,6 +16,7 @@ import (
 	"yadro.com/course/update/adapters/db"
 	"yadro.com/course/update/adapters/events"
 	updategrpc "yadro.com/course/update/adapters/grpc"
+	"yadro.com/course/update/adapters/scheduler"
 	"yadro.com/course/update/adapters/words"
 	"yadro.com/course/update/adapters/xkcd"
 	"yadro.com/course/update/config"
@@ -74,7 +75,15 @@ func run(cfg config.Config, log *slog.Logger) error {
 	defer notifier.Close()
 
 	// service
-	updater, err := core.NewService(log, storage, xkcd, words, cfg.XKCD.Concurrency, notifier)
+	updater, err := core.NewService(
+		log, storage, xkcd, words, cfg.XKCD.Concurrency, cfg.WordsBatch,
+		core.Backoff{
+			Attempts: cfg.XKCD.RetryAttempts,
+			Delay:    cfg.XKCD.RetryDelay,
+			MaxDelay: cfg.XKCD.RetryMaxDelay,
+		},
+		notifier,
+	)
 	if err != nil {
 		return fmt.Errorf("failed create Update service: %v", err)
 	}
@@ -93,6 +102,15 @@ func run(cfg config.Config, log *slog.Logger) error {
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer stop()
 
+	// scheduler, disabled by zero period without cron
+	if cfg.XKCD.CheckPeriod > 0 || cfg.XKCD.CheckCron != "" {
+		scheduler, err := scheduler.New(log, updater, cfg.XKCD.CheckPeriod, cfg.XKCD.CheckCron)
+		if err != nil {
+			return fmt.Errorf("failed create scheduler: %v", err)
+		}
+		scheduler.Run(ctx)
+	}
+
 	go func() {
 		<-ctx.Done()
 		log.Debug("shutting down server")
@@ -2,10 +2,16 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
+	"io"
 	"log"
 	"net"
+	"os"
+	"os/signal"
 	"strconv"
+	"syscall"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 	"google.golang.org/grpc"
@@ -17,10 +23,9 @@ import (
 	"yadro.com/course/words/words"
 )
 
-const maxPhraseLen = 20000
-
 type server struct {
 	wordspb.UnimplementedWordsServer
+	synonyms *words.Synonyms
 }
 
 func (s *server) Ping(_ context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
@@ -28,19 +33,156 @@ func (s *server) Ping(_ context.Context, _ *emptypb.Empty) (*emptypb.Empty, erro
 }
 
 func (s *server) Norm(_ context.Context, in *wordspb.WordsRequest) (*wordspb.WordsReply, error) {
-	if len(in.GetPhrase()) > maxPhraseLen {
-		return nil, status.Error(
-			codes.ResourceExhausted,
-			"phrase is large than "+strconv.Itoa(maxPhraseLen),
-		)
+	return norm(in)
+}
+
+func (s *server) NormBatch(
+	_ context.Context, in *wordspb.WordsBatchRequest,
+) (*wordspb.WordsBatchReply, error) {
+	reply := &wordspb.WordsBatchReply{Replies: make([]*wordspb.WordsReply, 0, len(in.GetRequests()))}
+	for _, req := range in.GetRequests() {
+		normalized, err := norm(req)
+		if err != nil {
+			return nil, err
+		}
+		reply.Replies = append(reply.Replies, normalized)
+	}
+	return reply, nil
+}
+
+func (s *server) NormStream(stream wordspb.Words_NormStreamServer) error {
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		reply, err := norm(req)
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(reply); err != nil {
+			return err
+		}
+	}
+}
+
+func norm(in *wordspb.WordsRequest) (*wordspb.WordsReply, error) {
+	language, err := checkRequest(in)
+	if err != nil {
+		return nil, err
 	}
+	normalize := words.Norm
+	if in.GetRepeats() {
+		normalize = words.Keywords
+	}
+	keywords, language := normalize(in.GetPhrase(), language)
 	return &wordspb.WordsReply{
-		Words: words.Norm(in.GetPhrase()),
+		Words:    keywords,
+		Language: string(language),
+		Version:  words.Version,
 	}, nil
 }
 
+func (s *server) Version(_ context.Context, _ *emptypb.Empty) (*wordspb.VersionReply, error) {
+	return &wordspb.VersionReply{Version: words.Version}, nil
+}
+
+func (s *server) Expand(_ context.Context, in *wordspb.WordsRequest) (*wordspb.ExpandReply, error) {
+	language, err := checkRequest(in)
+	if err != nil {
+		return nil, err
+	}
+	reply := &wordspb.ExpandReply{}
+	for _, synonym := range s.synonyms.Expand(in.GetPhrase(), language) {
+		reply.Synonyms = append(reply.Synonyms, &wordspb.Synonym{Words: synonym})
+	}
+	return reply, nil
+}
+
+func (s *server) Analyze(_ context.Context, in *wordspb.WordsRequest) (*wordspb.AnalyzeReply, error) {
+	return analyze(in)
+}
+
+func (s *server) AnalyzeBatch(
+	_ context.Context, in *wordspb.WordsBatchRequest,
+) (*wordspb.AnalyzeBatchReply, error) {
+	reply := &wordspb.AnalyzeBatchReply{Replies: make([]*wordspb.AnalyzeReply, 0, len(in.GetRequests()))}
+	for _, req := range in.GetRequests() {
+		analyzed, err := analyze(req)
+		if err != nil {
+			return nil, err
+		}
+		reply.Replies = append(reply.Replies, analyzed)
+	}
+	return reply, nil
+}
+
+func analyze(in *wordspb.WordsRequest) (*wordspb.AnalyzeReply, error) {
+	language, err := checkRequest(in)
+	if err != nil {
+		return nil, err
+	}
+	tokens, language := words.Analyze(in.GetPhrase(), language)
+	reply := &wordspb.AnalyzeReply{
+		Tokens:   make([]*wordspb.Token, 0, len(tokens)),
+		Language: string(language),
+	}
+	for _, t := range tokens {
+		reply.Tokens = append(reply.Tokens, &wordspb.Token{
+			Text:     t.Text,
+			Stem:     t.Stem,
+			Start:    int64(t.Start),
+			End:      int64(t.End),
+			Position: int64(t.Position),
+			Stop:     t.Stop,
+		})
+	}
+	return reply, nil
+}
+
+func checkRequest(in *wordspb.WordsRequest) (words.Language, error) {
+	if len(in.GetPhrase()) > wordspb.MaxPhraseLen {
+		return "", status.Error(
+			codes.ResourceExhausted,
+			"phrase is large than "+strconv.Itoa(wordspb.MaxPhraseLen),
+		)
+	}
+	language, err := words.ParseLanguage(in.GetLanguage())
+	if err != nil {
+		return "", status.Error(codes.InvalidArgument, err.Error())
+	}
+	return language, nil
+}
+
 type Config struct {
-	Address string `yaml:"words_address" env:"WORDS_ADDRESS" env-default:"80"`
+	Address             string        `yaml:"words_address" env:"WORDS_ADDRESS" env-default:"80"`
+	SynonymsFile        string        `yaml:"synonyms_file" env:"SYNONYMS_FILE"`
+	SynonymsCheckPeriod time.Duration `yaml:"synonyms_check_period" env:"SYNONYMS_CHECK_PERIOD" env-default:"1m"`
+}
+
+// reloadSynonyms rereads modified synonyms periodically and on SIGHUP.
+func reloadSynonyms(synonyms *words.Synonyms, period time.Duration) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-hup:
+		}
+		reloaded, err := synonyms.Reload()
+		if err != nil {
+			log.Printf("failed to reload synonyms: %v", err)
+			continue
+		}
+		if reloaded {
+			log.Printf("reloaded synonyms")
+		}
+	}
 }
 
 func main() {
@@ -53,13 +195,21 @@ func main() {
 		panic(err)
 	}
 
+	synonyms, err := words.NewSynonyms(cfg.SynonymsFile)
+	if err != nil {
+		log.Fatalf("failed to load synonyms: %v", err)
+	}
+	if cfg.SynonymsFile != "" {
+		go reloadSynonyms(synonyms, cfg.SynonymsCheckPeriod)
+	}
+
 	listener, err := net.Listen("tcp", cfg.Address)
 	if err != nil {
 		log.Fatalf("failed to listen: %v", err)
 	}
 
 	s := grpc.NewServer()
-	wordspb.RegisterWordsServer(s, &server{})
+	wordspb.RegisterWordsServer(s, &server{synonyms: synonyms})
 	reflection.Register(s)
 
 	if err := s.Serve(listener); err != nil {
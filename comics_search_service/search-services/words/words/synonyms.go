@@ -0,0 +1,146 @@
+package words
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Synonyms expands words by rules of a solr-style synonym file:
+//
+//	# comment
+//	car, automobile, auto     equivalent words, each expands to the others
+//	pc => personal computer   one-way rule, pc expands to personal computer
+//
+// Rules are matched by keywords, so "cars" expands as "car" does. A synonym
+// may consist of several words, a rule applies when a phrase has exactly
+// the keywords of its left side.
+type Synonyms struct {
+	path    string
+	modTime time.Time
+	rules   map[string][][]string // sorted keywords joined by space -> alternatives
+	lock    sync.RWMutex
+}
+
+// NewSynonyms loads synonyms from file, empty path means no synonyms.
+func NewSynonyms(path string) (*Synonyms, error) {
+	s := &Synonyms{path: path}
+	if path == "" {
+		return s, nil
+	}
+	if _, err := s.Reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Expand returns alternatives to keywords of phrase, each alternative is a list of keywords.
+func (s *Synonyms) Expand(phrase string, language Language) [][]string {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	keywords, _ := Norm(phrase, language)
+	return s.rules[key(keywords)]
+}
+
+// Reload reads the file again if it has been modified and reports whether it has.
+func (s *Synonyms) Reload() (bool, error) {
+	if s.path == "" {
+		return false, nil
+	}
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return false, err
+	}
+	s.lock.RLock()
+	modTime := s.modTime
+	s.lock.RUnlock()
+	if info.ModTime().Equal(modTime) {
+		return false, nil
+	}
+
+	file, err := os.Open(s.path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+	rules, err := parseSynonyms(file)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", s.path, err)
+	}
+
+	s.lock.Lock()
+	s.rules = rules
+	s.modTime = info.ModTime()
+	s.lock.Unlock()
+	return true, nil
+}
+
+func parseSynonyms(r io.Reader) (map[string][][]string, error) {
+	rules := make(map[string][][]string)
+	add := func(from, to []string) {
+		k := key(from)
+		for _, alternative := range rules[k] {
+			if slices.Equal(alternative, to) {
+				return
+			}
+		}
+		rules[k] = append(rules[k], to)
+	}
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text, _, _ := strings.Cut(scanner.Text(), "#")
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		left, right, explicit := strings.Cut(text, "=>")
+		from, err := synonymList(left)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if !explicit {
+			for _, a := range from {
+				for _, b := range from {
+					if key(a) != key(b) {
+						add(a, b)
+					}
+				}
+			}
+			continue
+		}
+		to, err := synonymList(right)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		for _, a := range from {
+			for _, b := range to {
+				if key(a) != key(b) {
+					add(a, b)
+				}
+			}
+		}
+	}
+	return rules, scanner.Err()
+}
+
+// synonymList normalizes comma separated synonyms.
+func synonymList(text string) ([][]string, error) {
+	var list [][]string
+	for _, synonym := range strings.Split(text, ",") {
+		keywords, _ := Norm(synonym, Auto)
+		if len(keywords) == 0 {
+			return nil, fmt.Errorf("no keywords in synonym %q", strings.TrimSpace(synonym))
+		}
+		list = append(list, keywords)
+	}
+	return list, nil
+}
+
+func key(keywords []string) string {
+	return strings.Join(slices.Sorted(slices.Values(keywords)), " ")
+}
@@ -1,25 +1,149 @@
 package words
 
 import (
-	"maps"
+	"fmt"
 	"slices"
 	"strings"
 	"unicode"
 
 	"github.com/kljensen/snowball/english"
+	"github.com/kljensen/snowball/russian"
 )
 
-func Norm(phrase string) []string {
-	words := make(map[string]bool)
-	splitted := strings.FieldsFunc(phrase, func(r rune) bool {
-		return !unicode.IsDigit(r) && !unicode.IsLetter(r)
-	})
-	for _, w := range splitted {
-		w := strings.ToLower(w)
-		if english.IsStopWord(w) {
-			continue
+// Version of normalization, it must be changed whenever Norm returns
+// other keywords for the same phrase, so that stored keywords are renewed.
+const Version = "2"
+
+type Language string
+
+const (
+	Auto    Language = "auto"
+	English Language = "en"
+	Russian Language = "ru"
+)
+
+type stemmer struct {
+	stem       func(word string, stemStopWords bool) string
+	isStopWord func(word string) bool
+}
+
+var stemmers = map[Language]stemmer{
+	English: {stem: english.Stem, isStopWord: english.IsStopWord},
+	Russian: {stem: russian.Stem, isStopWord: russian.IsStopWord},
+}
+
+// ParseLanguage accepts a supported language code, empty one means auto detection.
+func ParseLanguage(code string) (Language, error) {
+	language := Language(strings.ToLower(code))
+	if language == "" || language == Auto {
+		return Auto, nil
+	}
+	if _, ok := stemmers[language]; !ok {
+		return "", fmt.Errorf("unsupported language %q", code)
+	}
+	return language, nil
+}
+
+// Token is a word of a phrase.
+type Token struct {
+	Text     string // as written in phrase
+	Stem     string
+	Start    int // byte offsets in phrase
+	End      int
+	Position int  // number of the word in phrase
+	Stop     bool // stop words are not keywords
+}
+
+// Norm returns unique keywords of phrase and the language they were stemmed for.
+func Norm(phrase string, language Language) ([]string, Language) {
+	keywords, language := Keywords(phrase, language)
+	seen := make(map[string]bool, len(keywords))
+	return slices.DeleteFunc(keywords, func(keyword string) bool {
+		if seen[keyword] {
+			return true
+		}
+		seen[keyword] = true
+		return false
+	}), language
+}
+
+// Keywords returns keywords of phrase in order of its words, repeated
+// ones included, and the language they were stemmed for.
+func Keywords(phrase string, language Language) ([]string, Language) {
+	tokens, language := Analyze(phrase, language)
+	var keywords []string
+	for _, t := range tokens {
+		if !t.Stop {
+			keywords = append(keywords, t.Stem)
 		}
-		words[english.Stem(w, false)] = true
 	}
-	return slices.Collect(maps.Keys(words))
+	return keywords, language
+}
+
+// Analyze splits phrase into words and stems them. Each word is stemmed
+// by the language of its script in auto mode, the language of the most
+// words is returned then.
+func Analyze(phrase string, language Language) ([]Token, Language) {
+	var tokens []Token
+	counts := make(map[Language]int)
+	for _, t := range split(phrase) {
+		w := strings.ToLower(t.Text)
+		wordLanguage := language
+		if language == Auto {
+			wordLanguage = detect(w)
+		}
+		counts[wordLanguage]++
+		s := stemmers[wordLanguage]
+		t.Position = len(tokens)
+		t.Stop = s.isStopWord(w)
+		t.Stem = w
+		if !t.Stop {
+			t.Stem = s.stem(w, false)
+		}
+		tokens = append(tokens, t)
+	}
+	if language == Auto {
+		language = English
+		if counts[Russian] > counts[English] {
+			language = Russian
+		}
+	}
+	return tokens, language
+}
+
+// split returns words of phrase: sequences of letters and digits.
+func split(phrase string) []Token {
+	var tokens []Token
+	start := -1
+	for i, r := range phrase {
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		switch {
+		case isWord && start < 0:
+			start = i
+		case !isWord && start >= 0:
+			tokens = append(tokens, Token{Text: phrase[start:i], Start: start, End: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, Token{Text: phrase[start:], Start: start, End: len(phrase)})
+	}
+	return tokens
+}
+
+// detect guesses language of a word by its letters.
+func detect(word string) Language {
+	var cyrillic, other int
+	for _, r := range word {
+		switch {
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic++
+		case unicode.IsLetter(r):
+			other++
+		}
+	}
+	if cyrillic > other {
+		return Russian
+	}
+	return English
 }
@@ -0,0 +1,86 @@
+package words
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestKeywords(t *testing.T) {
+	keywords, language := Keywords("Linux, linux and more Linux kernels", English)
+	want := []string{"linux", "linux", "linux", "kernel"}
+	if !slices.Equal(keywords, want) {
+		t.Errorf("Keywords() = %v, want %v", keywords, want)
+	}
+	if language != English {
+		t.Errorf("language = %q, want %q", language, English)
+	}
+}
+
+func TestNorm(t *testing.T) {
+	keywords, _ := Norm("Linux, linux and more Linux kernels", English)
+	want := []string{"linux", "kernel"}
+	if !slices.Equal(keywords, want) {
+		t.Errorf("Norm() = %v, want %v", keywords, want)
+	}
+}
+
+func TestKeywordsRussian(t *testing.T) {
+	keywords, language := Keywords("Кошки и собаки бегали по улицам", Russian)
+	want := []string{"кошк", "собак", "бега", "улиц"}
+	if !slices.Equal(keywords, want) {
+		t.Errorf("Keywords() = %v, want %v", keywords, want)
+	}
+	if language != Russian {
+		t.Errorf("language = %q, want %q", language, Russian)
+	}
+}
+
+func TestKeywordsAuto(t *testing.T) {
+	tests := []struct {
+		phrase   string
+		keywords []string
+		language Language
+	}{
+		{phrase: "Linux kernels", keywords: []string{"linux", "kernel"}, language: English},
+		{phrase: "Кошки и собаки", keywords: []string{"кошк", "собак"}, language: Russian},
+		{phrase: "Linux и кошки", keywords: []string{"linux", "кошк"}, language: Russian},
+		{phrase: "Linux kernels и кошки", keywords: []string{"linux", "kernel", "кошк"}, language: English},
+		{phrase: "", keywords: nil, language: English},
+	}
+	for _, test := range tests {
+		t.Run(test.phrase, func(t *testing.T) {
+			keywords, language := Keywords(test.phrase, Auto)
+			if !slices.Equal(keywords, test.keywords) {
+				t.Errorf("Keywords() = %v, want %v", keywords, test.keywords)
+			}
+			if language != test.language {
+				t.Errorf("language = %q, want %q", language, test.language)
+			}
+		})
+	}
+}
+
+func TestParseLanguage(t *testing.T) {
+	tests := []struct {
+		code    string
+		want    Language
+		wantErr bool
+	}{
+		{code: "", want: Auto},
+		{code: "auto", want: Auto},
+		{code: "en", want: English},
+		{code: "RU", want: Russian},
+		{code: "de", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.code, func(t *testing.T) {
+			language, err := ParseLanguage(test.code)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("ParseLanguage() error = %v, want error %v", err, test.wantErr)
+			}
+			if language != test.want {
+				t.Errorf("ParseLanguage() = %q, want %q", language, test.want)
+			}
+		})
+	}
+}
@@ -2,8 +2,11 @@ package api_test
 
 import (
 	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
 	"net/url"
+	"strings"
 	"testing"
 	"time"
 
@@ -11,13 +14,18 @@ import (
 )
 
 type Comics struct {
-	ID  int    `json:"id"`
-	URL string `json:"url"`
+	ID      int     `json:"id"`
+	URL     string  `json:"url"`
+	Score   float64 `json:"score"`
+	Snippet string  `json:"snippet"`
 }
 
 type ComicsReply struct {
-	Comics []Comics `json:"comics"`
-	Total  int      `json:"total"`
+	Comics        []Comics `json:"comics"`
+	Total         int      `json:"total"`
+	NextPageToken string   `json:"next_page_token"`
+	Suggestion    string   `json:"suggestion"`
+	Corrected     bool     `json:"corrected"`
 }
 
 func TestSearch(t *testing.T) {
@@ -27,10 +35,34 @@ func TestSearch(t *testing.T) {
 	t.Run("no phrase", SearchNoPhrase)
 	t.Run("bad limit minus", SearchBadLimitMinus)
 	t.Run("bad limit alpha", SearchBadLimitAlpha)
+	t.Run("bad query syntax", SearchBadSyntax)
+	t.Run("bad query syntax reason", SearchBadSyntaxReason)
+	t.Run("bad fuzzy", SearchBadFuzzy)
 	t.Run("search limit 2", SearchLimit2)
 	t.Run("search limit default", SearchLimitDefault)
+	t.Run("search pages", SearchPages)
+	t.Run("search order", SearchOrder)
+	t.Run("search snippets", SearchSnippets)
 	t.Run("search phrases", SearchPhrases)
+	t.Run("search quoted phrase", SearchQuotedPhrase)
+	t.Run("search wildcard", SearchWildcard)
+	t.Run("search fuzzy", SearchFuzzy)
+	t.Run("search synonyms", SearchSynonyms)
+	t.Run("search field", SearchField)
+	t.Run("search filters", SearchFilters)
+	t.Run("search bad filters", SearchBadFilters)
+	t.Run("get comics", GetComics)
+	t.Run("get comics bad id", GetComicsBadID)
+	t.Run("get comics not found", GetComicsNotFound)
+	t.Run("list comics", ListComics)
+	t.Run("list comics bad sort", ListComicsBadSort)
 	t.Run("index search", IndexSearchPhrases)
+	t.Run("related comics", RelatedComics)
+	t.Run("related comics not found", RelatedComicsNotFound)
+	t.Run("suggest", Suggest)
+	t.Run("suggest no prefix", SuggestNoPrefix)
+	t.Run("did you mean", DidYouMean)
+	t.Run("nothing found", SearchNothingFound)
 }
 
 func SearchNoPhrase(t *testing.T) {
@@ -54,6 +86,39 @@ func SearchBadLimitAlpha(t *testing.T) {
 	require.Equal(t, http.StatusBadRequest, resp.StatusCode, "need bad request")
 }
 
+func SearchBadSyntax(t *testing.T) {
+	for _, phrase := range []string{`"linux`, "(linux OR", "linux AND", "-linux", "title:"} {
+		t.Run(phrase, func(t *testing.T) {
+			for _, endpoint := range []string{"/api/search", "/api/isearch"} {
+				resp, err := client.Get(address + endpoint + "?phrase=" + url.QueryEscape(phrase))
+				require.NoError(t, err, "failed to search")
+				resp.Body.Close()
+				require.Equal(t, http.StatusBadRequest, resp.StatusCode, "need bad request")
+			}
+		})
+	}
+}
+
+func SearchBadSyntaxReason(t *testing.T) {
+	for _, endpoint := range []string{"/api/search", "/api/isearch"} {
+		resp, err := client.Get(address + endpoint + "?phrase=" + url.QueryEscape(`linux "kernel`))
+		require.NoError(t, err, "failed to search")
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		require.NoError(t, err, "failed to read reply")
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode, "need bad request")
+		require.Contains(t, string(body), "unterminated phrase at position 6", "need reason of the error")
+		require.Equal(t, 1, strings.Count(string(body), "not acceptable"), "reason is told once")
+	}
+}
+
+func SearchBadFuzzy(t *testing.T) {
+	resp, err := client.Get(address + "/api/search?phrase=linux&fuzzy=maybe")
+	require.NoError(t, err, "failed to search")
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode, "need bad request")
+}
+
 func SearchLimit2(t *testing.T) {
 	resp, err := client.Get(address + "/api/search?limit=2&phrase=linux")
 	require.NoError(t, err, "failed to search")
@@ -61,8 +126,8 @@ func SearchLimit2(t *testing.T) {
 	require.Equal(t, http.StatusOK, resp.StatusCode, "need OK status")
 	var comics ComicsReply
 	require.NoError(t, json.NewDecoder(resp.Body).Decode(&comics), "decode failed")
-	require.Equal(t, 2, comics.Total)
-	require.Equal(t, 2, len(comics.Comics))
+	require.Equal(t, searchTotal(t, "linux"), comics.Total, "total counts all matches")
+	require.Equal(t, 2, len(comics.Comics), "comics are limited")
 }
 
 func SearchLimitDefault(t *testing.T) {
@@ -73,8 +138,68 @@ func SearchLimitDefault(t *testing.T) {
 	require.Equal(t, http.StatusOK, resp.StatusCode, "need OK status")
 	var comics ComicsReply
 	require.NoError(t, json.NewDecoder(resp.Body).Decode(&comics), "decode failed")
-	require.Equal(t, 10, comics.Total)
-	require.Equal(t, 10, len(comics.Comics))
+	require.Equal(t, searchTotal(t, "linux"), comics.Total, "total counts all matches")
+	require.Equal(t, 10, len(comics.Comics), "comics are limited")
+}
+
+// searchTotal returns the number of comics found by phrase fetching all of them.
+func searchTotal(t *testing.T, phrase string) int {
+	all := searchPage(t, "limit=1000&phrase="+url.QueryEscape(phrase))
+	require.Less(t, len(all.Comics), 1000, "all comics must fit a page")
+	require.Equal(t, len(all.Comics), all.Total)
+	return len(all.Comics)
+}
+
+func searchPage(t *testing.T, query string) ComicsReply {
+	resp, err := client.Get(address + "/api/search?" + query)
+	require.NoError(t, err, "failed to search")
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode, "need OK status")
+	var comics ComicsReply
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&comics), "decode failed")
+	return comics
+}
+
+// SearchOrder checks that comics fetched from DB at once keep the order of relevance.
+func SearchOrder(t *testing.T) {
+	for _, page := range []func(*testing.T, string) ComicsReply{searchPage, isearchPage} {
+		found := page(t, "phrase="+url.QueryEscape("linux OR windows OR computer")+"&limit=1000")
+		require.Greater(t, len(found.Comics), 10, "need many comics fetched at once")
+		for i := 1; i < len(found.Comics); i++ {
+			prev, c := found.Comics[i-1], found.Comics[i]
+			require.GreaterOrEqual(t, prev.Score, c.Score, "comics must be ordered by relevance")
+			if prev.Score == c.Score {
+				require.Less(t, prev.ID, c.ID, "comics of equal relevance must be ordered by ID")
+			}
+		}
+	}
+}
+
+func SearchPages(t *testing.T) {
+	all := searchPage(t, "phrase=linux&limit=1000")
+	require.Equal(t, all.Total, len(all.Comics))
+	require.Empty(t, all.NextPageToken)
+
+	var paged []Comics
+	page := searchPage(t, "phrase=linux&limit=3")
+	paged = append(paged, page.Comics...)
+	for page.NextPageToken != "" {
+		require.Equal(t, all.Total, page.Total)
+		page = searchPage(t, "phrase=linux&limit=3&page_token="+url.QueryEscape(page.NextPageToken))
+		paged = append(paged, page.Comics...)
+	}
+	require.Equal(t, all.Comics, paged, "pages must follow the same order")
+
+	offset := searchPage(t, "phrase=linux&limit=3&offset=3")
+	require.Equal(t, all.Comics[3:6], offset.Comics)
+}
+
+func SearchSnippets(t *testing.T) {
+	comics := searchPage(t, "phrase=linux&limit=3")
+	require.NotEmpty(t, comics.Comics)
+	for _, c := range comics.Comics {
+		require.Contains(t, strings.ToLower(c.Snippet), "<em>linux</em>", "no highlight in snippet")
+	}
 }
 
 func SearchPhrases(t *testing.T) {
@@ -121,6 +246,167 @@ func SearchPhrases(t *testing.T) {
 	}
 }
 
+func hasURL(comics []Comics, url string) bool {
+	for _, c := range comics {
+		if c.URL == url {
+			return true
+		}
+	}
+	return false
+}
+
+func SearchQuotedPhrase(t *testing.T) {
+	const sandwich = "https://imgs.xkcd.com/comics/sandwich.png"
+	for _, page := range []func(*testing.T, string) ComicsReply{searchPage, isearchPage} {
+		inOrder := page(t, "limit=1000&phrase="+url.QueryEscape(`"sudo make me a sandwich"`))
+		require.True(t, hasURL(inOrder.Comics, sandwich), "phrase must match its words in order")
+
+		outOfOrder := page(t, "limit=1000&phrase="+url.QueryEscape(`"sandwich sudo"`))
+		require.False(t, hasURL(outOfOrder.Comics, sandwich), "phrase must not match words out of order")
+
+		keywords := page(t, "limit=1000&phrase="+url.QueryEscape("sandwich AND sudo"))
+		require.True(t, hasURL(keywords.Comics, sandwich), "keywords match in any order")
+	}
+}
+
+func SearchWildcard(t *testing.T) {
+	resp, err := client.Get(address + "/api/search?phrase=" + url.QueryEscape("linu*"))
+	require.NoError(t, err, "failed to search")
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode, "need OK status")
+	var comics ComicsReply
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&comics), "decode failed")
+	require.NotEmpty(t, comics.Comics, "wildcard must match linux")
+}
+
+func SearchFuzzy(t *testing.T) {
+	resp, err := client.Get(address + "/api/search?phrase=linxu&fuzzy=true")
+	require.NoError(t, err, "failed to search")
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode, "need OK status")
+	var comics ComicsReply
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&comics), "decode failed")
+	require.NotEmpty(t, comics.Comics, "fuzzy search must tolerate a typo")
+}
+
+func SearchSynonyms(t *testing.T) {
+	car := searchPage(t, "phrase=car&limit=1000")
+	automobile := searchPage(t, "phrase=automobile&limit=1000")
+	require.NotEmpty(t, automobile.Comics)
+	require.Equal(t, car.Total, automobile.Total, "equivalent synonyms find the same comics")
+}
+
+func SearchField(t *testing.T) {
+	all := searchPage(t, "phrase=linux")
+	for _, field := range []string{"title", "alt", "transcript"} {
+		t.Run(field, func(t *testing.T) {
+			comics := searchPage(t, "phrase="+url.QueryEscape(field+":linux"))
+			require.LessOrEqual(t, comics.Total, all.Total, "field must narrow search")
+		})
+	}
+}
+
+func SearchFilters(t *testing.T) {
+	all := searchPage(t, "phrase=linux&limit=1000")
+
+	byID := searchPage(t, "phrase=linux&limit=1000&min_id=100&max_id=500")
+	require.LessOrEqual(t, byID.Total, all.Total)
+	for _, c := range byID.Comics {
+		require.GreaterOrEqual(t, c.ID, 100)
+		require.LessOrEqual(t, c.ID, 500)
+	}
+
+	byDate := searchPage(t, "phrase=linux&limit=1000&from=2010&to=2012")
+	require.LessOrEqual(t, byDate.Total, all.Total)
+}
+
+func SearchBadFilters(t *testing.T) {
+	for _, query := range []string{"from=yesterday", "to=2010-13", "min_id=-1", "from=2012&to=2010", "min_id=5&max_id=1"} {
+		t.Run(query, func(t *testing.T) {
+			for _, endpoint := range []string{"/api/search", "/api/isearch"} {
+				resp, err := client.Get(address + endpoint + "?phrase=linux&" + query)
+				require.NoError(t, err, "failed to search")
+				resp.Body.Close()
+				require.Equal(t, http.StatusBadRequest, resp.StatusCode, "need bad request")
+			}
+		})
+	}
+}
+
+type ComicsInfo struct {
+	ID  int    `json:"id"`
+	URL string `json:"url"`
+}
+
+func GetComics(t *testing.T) {
+	found := searchPage(t, "phrase="+url.QueryEscape("Binary Christmas Tree")+"&limit=1")
+	require.NotEmpty(t, found.Comics)
+
+	resp, err := client.Get(fmt.Sprintf("%s/api/comics/%d", address, found.Comics[0].ID))
+	require.NoError(t, err, "failed to get comics")
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode, "need OK status")
+	var comics ComicsInfo
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&comics), "decode failed")
+	require.Equal(t, found.Comics[0].ID, comics.ID)
+	require.Equal(t, found.Comics[0].URL, comics.URL)
+}
+
+func GetComicsBadID(t *testing.T) {
+	resp, err := client.Get(address + "/api/comics/abc")
+	require.NoError(t, err, "failed to get comics")
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode, "need bad request")
+}
+
+func GetComicsNotFound(t *testing.T) {
+	resp, err := client.Get(address + "/api/comics/1000000")
+	require.NoError(t, err, "failed to get comics")
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusNotFound, resp.StatusCode, "need not found")
+}
+
+type ListReply struct {
+	Comics []ComicsInfo `json:"comics"`
+	Total  int          `json:"total"`
+}
+
+func listPage(t *testing.T, query string) ListReply {
+	resp, err := client.Get(address + "/api/comics?" + query)
+	require.NoError(t, err, "failed to list comics")
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode, "need OK status")
+	var comics ListReply
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&comics), "decode failed")
+	return comics
+}
+
+func ListComics(t *testing.T) {
+	first := listPage(t, "limit=5")
+	require.Len(t, first.Comics, 5)
+	require.Equal(t, 1, first.Comics[0].ID)
+	for i := 1; i < len(first.Comics); i++ {
+		require.Less(t, first.Comics[i-1].ID, first.Comics[i].ID)
+	}
+
+	second := listPage(t, "limit=5&offset=5")
+	require.Len(t, second.Comics, 5)
+	require.Less(t, first.Comics[4].ID, second.Comics[0].ID)
+
+	last := listPage(t, "limit=5&sort=-id")
+	require.Equal(t, first.Total, last.Total)
+	for i := 1; i < len(last.Comics); i++ {
+		require.Greater(t, last.Comics[i-1].ID, last.Comics[i].ID)
+	}
+}
+
+func ListComicsBadSort(t *testing.T) {
+	resp, err := client.Get(address + "/api/comics?sort=random")
+	require.NoError(t, err, "failed to list comics")
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode, "need bad request")
+}
+
 func IndexSearchPhrases(t *testing.T) {
 	// clean DB and wait a few moments for index update
 	prepare(t)
@@ -182,3 +468,106 @@ func IndexSearchPhrases(t *testing.T) {
 		})
 	}
 }
+
+func RelatedComics(t *testing.T) {
+	found := searchPage(t, "phrase="+url.QueryEscape("Binary Christmas Tree")+"&limit=1")
+	require.NotEmpty(t, found.Comics)
+	ID := found.Comics[0].ID
+
+	resp, err := client.Get(fmt.Sprintf("%s/api/comics/%d/related?limit=3", address, ID))
+	require.NoError(t, err, "failed to get related comics")
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode, "need OK status")
+	var related ComicsReply
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&related), "decode failed")
+	require.NotEmpty(t, related.Comics)
+	require.LessOrEqual(t, len(related.Comics), 3)
+	for i, c := range related.Comics {
+		require.NotEqual(t, ID, c.ID, "comics is not related to itself")
+		if i > 0 {
+			require.GreaterOrEqual(t, related.Comics[i-1].Score, c.Score)
+		}
+	}
+}
+
+func RelatedComicsNotFound(t *testing.T) {
+	resp, err := client.Get(address + "/api/comics/1000000/related")
+	require.NoError(t, err, "failed to get related comics")
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusNotFound, resp.StatusCode, "need not found")
+}
+
+type Suggestion struct {
+	Text      string `json:"text"`
+	Keyword   string `json:"keyword"`
+	Frequency int    `json:"frequency"`
+}
+
+type SuggestReply struct {
+	Suggestions []Suggestion `json:"suggestions"`
+}
+
+func Suggest(t *testing.T) {
+	resp, err := client.Get(address + "/api/suggest?prefix=LINU&limit=3")
+	require.NoError(t, err, "failed to suggest")
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode, "need OK status")
+	var reply SuggestReply
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&reply), "decode failed")
+	require.NotEmpty(t, reply.Suggestions)
+	require.LessOrEqual(t, len(reply.Suggestions), 3)
+	require.Equal(t, "linux", reply.Suggestions[0].Text)
+	for i, s := range reply.Suggestions {
+		require.True(t, strings.HasPrefix(s.Text, "linu"))
+		if i > 0 {
+			require.GreaterOrEqual(t, reply.Suggestions[i-1].Frequency, s.Frequency)
+		}
+	}
+}
+
+func SuggestNoPrefix(t *testing.T) {
+	resp, err := client.Get(address + "/api/suggest")
+	require.NoError(t, err, "failed to suggest")
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode, "need bad request")
+}
+
+func isearchPage(t *testing.T, query string) ComicsReply {
+	resp, err := client.Get(address + "/api/isearch?" + query)
+	require.NoError(t, err, "failed to search")
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode, "need OK status")
+	var comics ComicsReply
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&comics), "decode failed")
+	return comics
+}
+
+func DidYouMean(t *testing.T) {
+	for _, page := range []func(*testing.T, string) ComicsReply{searchPage, isearchPage} {
+		found := page(t, "phrase=linux")
+		require.NotEmpty(t, found.Comics)
+		require.Empty(t, found.Suggestion)
+
+		misspelled := page(t, "phrase=linuz")
+		require.Empty(t, misspelled.Comics)
+		require.Equal(t, "linux", misspelled.Suggestion)
+		require.False(t, misspelled.Corrected)
+
+		corrected := page(t, "phrase=linuz&autocorrect=true")
+		require.True(t, corrected.Corrected)
+		require.Equal(t, "linux", corrected.Suggestion)
+		require.Equal(t, found.Total, corrected.Total)
+	}
+}
+
+// SearchNothingFound checks that a query found nothing is not an error, it has no comics.
+func SearchNothingFound(t *testing.T) {
+	for _, page := range []func(*testing.T, string) ComicsReply{searchPage, isearchPage} {
+		nothing := page(t, "phrase=qwxzvbnmpl")
+		require.NotNil(t, nothing.Comics)
+		require.Empty(t, nothing.Comics)
+		require.Equal(t, 0, nothing.Total)
+		require.Empty(t, nothing.Suggestion)
+		require.Empty(t, nothing.NextPageToken)
+	}
+}
@@ -1,11 +1,13 @@
 package api_test
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -20,14 +22,62 @@ type UpdateStats struct {
 	ComicsTotal   int `json:"comics_total"`
 }
 
+type UpdateProgress struct {
+	Started    string `json:"started"`
+	Finished   string `json:"finished"`
+	Planned    int    `json:"planned"`
+	Fetched    int    `json:"fetched"`
+	Normalized int    `json:"normalized"`
+	Stored     int    `json:"stored"`
+	Failed     int    `json:"failed"`
+}
+
 type UpdateStatus struct {
-	Status string `json:"status"`
+	Status   string          `json:"status"`
+	Progress *UpdateProgress `json:"progress"`
+}
+
+type CancelReply struct {
+	Planned  int  `json:"planned"`
+	Stored   int  `json:"stored"`
+	Failed   int  `json:"failed"`
+	Canceled bool `json:"canceled"`
+}
+
+type Failure struct {
+	ID       int    `json:"id"`
+	Stage    string `json:"stage"`
+	Error    string `json:"error"`
+	Attempts int    `json:"attempts"`
+}
+
+type FailuresReply struct {
+	Failures []Failure `json:"failures"`
 }
 
 func TestEmptyDB(t *testing.T) {
 	prepare(t)
 }
 
+func TestRetryFailed(t *testing.T) {
+	prepare(t)
+	require.Empty(t, failures(t), "drop must clear failures")
+
+	resp, err := client.Post(address+"/api/db/failures/retry", "", nil)
+	require.NoError(t, err, "could not send retry command")
+	resp.Body.Close()
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	req, err := http.NewRequest(http.MethodPost, address+"/api/db/failures/retry", nil)
+	require.NoError(t, err, "cannot make request")
+	req.Header.Add("Authorization", "Token "+login(t))
+	resp, err = client.Do(req)
+	require.NoError(t, err, "could not send retry command")
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, 0, stats(t).ComicsFetched, "nothing to retry")
+}
+
 func TestUpdate(t *testing.T) {
 	prepare(t)
 	var wg sync.WaitGroup
@@ -65,7 +115,87 @@ func TestUpdate(t *testing.T) {
 	require.True(t, 1000 < st.WordsTotal, "not enough total words in DB")
 	require.True(t, 100 < st.WordsUnique, "not enough unique words in DB")
 
+	progress := fullStatus(t).Progress
+	require.NotNil(t, progress, "need progress of the finished update")
+	require.NotEmpty(t, progress.Finished)
+	require.Equal(t, progress.Planned, progress.Stored+progress.Failed)
+	require.Equal(t, st.ComicsFetched, progress.Stored)
+	require.Len(t, failures(t), progress.Failed)
+
+	// everything is normalized by the current version already
+	require.Equal(t, http.StatusOK, reindex(t, token))
+	require.Equal(t, st, stats(t))
+	progress = fullStatus(t).Progress
+	require.NotNil(t, progress, "need progress of the reindex")
+	require.Equal(t, 0, progress.Planned)
+
+	prepare(t)
+}
+
+func TestCancelUpdate(t *testing.T) {
+	prepare(t)
+	token := login(t)
+
+	code, _ := cancelUpdate(t, "")
+	require.Equal(t, http.StatusUnauthorized, code)
+	code, _ = cancelUpdate(t, token)
+	require.Equal(t, http.StatusNotFound, code, "no update to cancel")
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	var res int
+	var err error
+	go func() {
+		res, err = update(token)
+		wg.Done()
+	}()
+	require.Eventually(t, func() bool {
+		status, err := status()
+		return err == nil && status == "running"
+	}, 10*time.Second, 100*time.Millisecond, "need running update")
+
+	code, reply := cancelUpdate(t, token)
+	require.Equal(t, http.StatusOK, code)
+	wg.Wait()
+	require.NoError(t, err, "error from update")
+	require.Equal(t, http.StatusConflict, res, "update must be canceled")
+
+	require.True(t, reply.Canceled)
+	require.True(t, reply.Stored < reply.Planned, "update must stop before the end")
+	require.Equal(t, 0, reply.Failed, "canceled comics are not failed")
+	require.Equal(t, reply.Stored, stats(t).ComicsFetched, "stored comics are kept")
+	updateStatus, err := status()
+	require.Equal(t, "idle", updateStatus, err)
+
+	prepare(t)
+}
+
+func TestReindexEmptyDB(t *testing.T) {
 	prepare(t)
+	require.Equal(t, http.StatusUnauthorized, reindex(t, ""))
+	require.Equal(t, http.StatusOK, reindex(t, login(t)))
+	require.Equal(t, 0, stats(t).ComicsFetched)
+}
+
+func TestUpdateStatusStream(t *testing.T) {
+	resp, err := client.Get(address + "/api/db/status/stream")
+	require.NoError(t, err, "could not watch status")
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		var status UpdateStatus
+		require.NoError(t, json.Unmarshal([]byte(data), &status), "cannot decode")
+		require.Equal(t, "idle", status.Status)
+		return
+	}
+	t.Fatal("no status event received")
 }
 
 func login(t *testing.T) string {
@@ -132,6 +262,54 @@ func status() (string, error) {
 	return status.Status, nil
 }
 
+func fullStatus(t *testing.T) UpdateStatus {
+	resp, err := client.Get(address + "/api/db/status")
+	require.NoError(t, err, "could not get status")
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var status UpdateStatus
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&status), "cannot decode")
+	return status
+}
+
+func cancelUpdate(t *testing.T, token string) (int, CancelReply) {
+	req, err := http.NewRequest(http.MethodDelete, address+"/api/db/update", nil)
+	require.NoError(t, err, "cannot make request")
+	if token != "" {
+		req.Header.Add("Authorization", "Token "+token)
+	}
+	resp, err := client.Do(req)
+	require.NoError(t, err, "could not send cancel command")
+	defer resp.Body.Close()
+	var reply CancelReply
+	if resp.StatusCode == http.StatusOK {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&reply), "cannot decode")
+	}
+	return resp.StatusCode, reply
+}
+
+func reindex(t *testing.T, token string) int {
+	req, err := http.NewRequest(http.MethodPost, address+"/api/db/reindex", nil)
+	require.NoError(t, err, "cannot make request")
+	if token != "" {
+		req.Header.Add("Authorization", "Token "+token)
+	}
+	resp, err := client.Do(req)
+	require.NoError(t, err, "could not send reindex command")
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func failures(t *testing.T) []Failure {
+	resp, err := client.Get(address + "/api/db/failures")
+	require.NoError(t, err, "could not get failures")
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var reply FailuresReply
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&reply), "cannot decode")
+	return reply.Failures
+}
+
 func stats(t *testing.T) UpdateStats {
 	resp, err := client.Get(address + "/api/db/stats")
 	require.NoError(t, err, "could not get stats")